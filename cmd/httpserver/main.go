@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
-	"github.com/joakim-ribier/go-utils/pkg/iosutil"
-	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
 	"github.com/joakim-ribier/go-utils/pkg/logsutil"
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
 	"github.com/joakim-ribier/go-utils/pkg/stringsutil"
 	"github.com/joakim-ribier/mockapic/internal"
 	"github.com/joakim-ribier/mockapic/internal/server"
@@ -20,11 +25,37 @@ func main() {
 	reqMaxLimit := flag.Int("req_max", stringsutil.Int(os.Getenv("MOCKAPIC_REQ_MAX_LIMIT"), -1), "define the nb requests max limit")
 	port := flag.String("port", stringsutil.OrElse(os.Getenv("MOCKAPIC_PORT"), "3333"), "define the server [port]")
 	workingDir := flag.String("home", stringsutil.OrElse(os.Getenv("MOCKAPIC_HOME"), "."), "define the [working home] directory")
+	bodyDir := flag.String("body_dir", os.Getenv("MOCKAPIC_BODY_DIR"), "define the [directory] of the body files (default {home}/files)")
 
 	ssl := flag.Bool("ssl", stringsutil.Bool(stringsutil.OrElse(os.Getenv("MOCKAPIC_SSL"), "false")), "enable [ssl] mode")
 	certificatesDir := flag.String("cert", os.Getenv("MOCKAPIC_CERT"), "define the [certificate] directory that contains the *crt and the *key files if [ssl] mode enabled")
 	crtFilePath := flag.String("crt", os.Getenv("MOCKAPIC_CRT_FILE_PATH"), "define the [*crt] file path if [ssl] mode enabled")
 	keyFilePath := flag.String("key", os.Getenv("MOCKAPIC_KEY_FILE_PATH"), "define the [*key] file path if [ssl] mode enabled")
+	clientCA := flag.String("client_ca", os.Getenv("MOCKAPIC_CLIENT_CA"), "define the [client CA] file path which verifies the client certificates (mTLS) if [ssl] mode enabled")
+	clientAuth := flag.String("client_auth", stringsutil.OrElse(os.Getenv("MOCKAPIC_CLIENT_AUTH"), server.CLIENT_AUTH_REQUIRE), "define if the client certificate is required (require) or verified if given (verify_if_given) with the [client CA]")
+	sslPort := flag.String("ssl_port", os.Getenv("MOCKAPIC_SSL_PORT"), "define the [ssl port] which serves the https requests along with the http requests on the [port]")
+	ports := flag.String("ports", os.Getenv("MOCKAPIC_PORTS"), "define the additional [ports] each one bound to a namespace ([tls:]{port}={namespace},...)")
+	enableHTTP2 := flag.Bool("http2", stringsutil.Bool(stringsutil.OrElse(os.Getenv("MOCKAPIC_HTTP2"), "true")), "enable [http2] on the TLS ports and h2c (HTTP/2 cleartext) on the plain ports")
+	readTimeout := flag.Duration("read_timeout", durationOrElse(os.Getenv("MOCKAPIC_READ_TIMEOUT"), server.READ_TIMEOUT), "define the [read timeout] of the requests")
+	writeTimeout := flag.Duration("write_timeout", durationOrElse(os.Getenv("MOCKAPIC_WRITE_TIMEOUT"), server.WRITE_TIMEOUT), "define the [write timeout] of the responses")
+	idleTimeout := flag.Duration("idle_timeout", durationOrElse(os.Getenv("MOCKAPIC_IDLE_TIMEOUT"), server.IDLE_TIMEOUT), "define the [idle timeout] of the keep-alive connections")
+	shutdownTimeout := flag.Duration("shutdown_timeout", durationOrElse(os.Getenv("MOCKAPIC_SHUTDOWN_TIMEOUT"), server.SHUTDOWN_TIMEOUT), "define the [time] to complete the in-flight requests on shutdown (SIGTERM)")
+	sslHosts := flag.String("ssl_hosts", stringsutil.OrElse(os.Getenv("MOCKAPIC_SSL_HOSTS"), strings.Join(server.SSL_HOSTS, ",")), "define the DNS names and IP addresses of the certificate generated if the [*crt] and [*key] files do not exist")
+
+	predefined := flag.String("predefined", os.Getenv("MOCKAPIC_PREDEFINED"), "define the [predefined] requests files (*.json or *.yaml), glob patterns or directories separated by a comma (default {home}/mockapic.json,{home}/mockapic.yaml)")
+	predefinedDir := flag.String("predefined_dir", os.Getenv("MOCKAPIC_PREDEFINED_DIR"), "define a directory of [predefined] requests *.json and *.yaml files")
+	watch := flag.Bool("watch", stringsutil.Bool(stringsutil.OrElse(os.Getenv("MOCKAPIC_WATCH"), "true")), "enable the hot reload of the [predefined] requests files")
+
+	quotaMocks := flag.Int("quota_mocks", stringsutil.Int(os.Getenv("MOCKAPIC_QUOTA_MOCKS"), -1), "define the nb stored requests max limit of each client")
+	quotaRate := flag.Int("quota_rate", stringsutil.Int(os.Getenv("MOCKAPIC_QUOTA_RATE"), -1), "define the nb created requests per hour max limit of each client")
+	rateLimit := flag.String("rate_limit", os.Getenv("MOCKAPIC_RATE_LIMIT"), "define the [rate limits] of the requests by path prefix ({prefix}={limit}/{period},...)")
+	storage := flag.String("storage", stringsutil.OrElse(os.Getenv("MOCKAPIC_STORAGE"), internal.STORAGE_FILE), "define the [storage] backend (file, memory or bolt) of the requests")
+	eviction := flag.String("eviction", stringsutil.OrElse(os.Getenv("MOCKAPIC_EVICTION"), internal.EVICTION_CREATED), "define the [eviction] policy (created, lru or lfu) of the requests over the max limit")
+	apiKeys := flag.String("api_keys", os.Getenv("MOCKAPIC_API_KEYS"), "define the [api keys] of the management endpoints with their role ({key}:{ro|rw},...)")
+	basicAuth := flag.String("basic_auth", os.Getenv("MOCKAPIC_BASIC_AUTH"), "define the [basic auth] users of the management endpoints with their role ({user}:{password}:{ro|rw},...)")
+	oauthClients := flag.String("oauth_clients", os.Getenv("MOCKAPIC_OAUTH_CLIENTS"), "define the [oauth2 clients] of the token endpoint ({id}:{secret},...), all the clients are allowed if empty")
+	oauthTokenTTL := flag.Duration("oauth_token_ttl", durationOrElse(os.Getenv("MOCKAPIC_OAUTH_TOKEN_TTL"), server.OAUTH_TOKEN_TTL), "define the [time-to-live] of the oauth2 access tokens")
+	sessionTTL := flag.Duration("session_ttl", durationOrElse(os.Getenv("MOCKAPIC_SESSION_TTL"), server.SESSION_TTL), "define the [time-to-live] of the sessions without activity")
 
 	flag.Parse()
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
@@ -34,68 +65,184 @@ func main() {
 		log.Fatalf("%v", err)
 	}
 
+	if !slicesutil.Exist(internal.EVICTION_POLICIES, *eviction) {
+		log.Fatalf("'--eviction' parameter {%s} must be one of %v.", *eviction, internal.EVICTION_POLICIES)
+	}
+
+	if !slicesutil.Exist(server.CLIENT_AUTHS, *clientAuth) {
+		log.Fatalf("'--client_auth' parameter {%s} must be one of %v.", *clientAuth, server.CLIENT_AUTHS)
+	}
+
+	listeners, err := server.ParseListeners(*ports)
+	if err != nil {
+		log.Fatalf("'--ports' parameter {%s} must be formatted as [tls:]{port}={namespace},...\n%v", *ports, err)
+	}
+	*ssl = *ssl || *sslPort != ""
+	tls := *ssl || slicesutil.ExistT(listeners, func(l server.Listener) bool { return l.TLS })
+
+	rateLimits, err := server.ParseRateLimits(*rateLimit)
+	if err != nil {
+		log.Fatalf("'--rate_limit' parameter {%s} must be formatted as {prefix}={limit}/{period}.\n%v", *rateLimit, err)
+	}
+
+	auth, err := server.ParseAuth(*apiKeys, *basicAuth)
+	if err != nil {
+		log.Fatalf("'--api_keys' and '--basic_auth' parameters must be formatted as {key}:{role} and {user}:{password}:{role}.\n%v", err)
+	}
+
+	clients, err := server.ParseOAuthClients(*oauthClients)
+	if err != nil {
+		log.Fatalf("'--oauth_clients' parameter must be formatted as {id}:{secret},...\n%v", err)
+	}
+
 	if _, err := os.Open(*workingDir); err != nil {
 		log.Fatalf("'--home' parameter {%s} must be a valid directory.\n%v", *workingDir, err)
 	}
-	requestsDir := *workingDir + "/requests"
-	requestsPredefinedFile := *workingDir + "/mockapic.json"
-	*certificatesDir = genericsutil.When[bool, string](*ssl, func(b bool) bool { return *ssl && *certificatesDir == "" }, *workingDir, *certificatesDir)
+	predefinedSources := slicesutil.FilterByNonEmpty(strings.Split(stringsutil.OrElse(*predefined, *workingDir+"/mockapic.json,"+*workingDir+"/mockapic.yaml"), ","))
+	if *predefinedDir != "" {
+		predefinedSources = append(predefinedSources, *predefinedDir)
+	}
+	*certificatesDir = genericsutil.When[bool, string](tls, func(b bool) bool { return tls && *certificatesDir == "" }, *workingDir, *certificatesDir)
+
+	sslConfig := server.NewSSL(*ssl, *certificatesDir, *crtFilePath, *keyFilePath).
+		WithPort(*sslPort).
+		WithHosts(slicesutil.FilterByNonEmpty(strings.Split(*sslHosts, ","))).
+		WithClientCA(*clientCA, *clientAuth)
+
+	*bodyDir = stringsutil.OrElse(*bodyDir, *workingDir+"/"+internal.BODY_FILES_DIRECTORY)
+	protected := append([]string{*workingDir + "/requests", *workingDir + "/mockapic.db", *workingDir + "/application.log", *workingDir + "/remote-addr.json"}, sslConfig.Files()...)
+	if err := internal.CheckBodyDirectory(*bodyDir, protected); err != nil {
+		log.Fatalf("'--body_dir' parameter {%s} must not contain the storage, the logs or the certificates.\n%v", *bodyDir, err)
+	}
 
 	logger.Info(internal.LOGO,
 		"home", workingDir,
+		"body_dir", bodyDir,
 		"port", port,
 		"ssl", ssl,
+		"ssl_port", sslPort,
+		"ports", ports,
+		"http2", enableHTTP2,
+		"read_timeout", readTimeout,
+		"write_timeout", writeTimeout,
+		"idle_timeout", idleTimeout,
+		"shutdown_timeout", shutdownTimeout,
 		"cert", certificatesDir,
 		"crt", crtFilePath,
 		"key", keyFilePath,
+		"ssl_hosts", sslHosts,
+		"client_ca", clientCA,
+		"client_auth", clientAuth,
 		"req_max", reqMaxLimit,
+		"storage", storage,
+		"eviction", eviction,
+		"quota_mocks", quotaMocks,
+		"quota_rate", quotaRate,
+		"rate_limit", rateLimit,
+		"session_ttl", sessionTTL,
+		"auth", auth != nil,
+		"oauth_clients", len(clients),
+		"oauth_token_ttl", oauthTokenTTL,
+		"predefined", predefinedSources,
+		"watch", watch,
 	)
 
-	err = os.MkdirAll(requestsDir, os.ModePerm)
+	store, namespacesStorage, err := internal.OpenStorage(*storage, *workingDir, *eviction, *logger)
 	if err != nil {
-		log.Fatalf("%v", err)
-	}
-
-	predefinedMockedRequests := []internal.PredefinedMockedRequest{}
-	data, err := iosutil.Load(requestsPredefinedFile)
-	if err != nil {
-		logger.Error(err, fmt.Sprintf("file {%s} not found", requestsPredefinedFile))
-	} else {
-		predefinedMockedRequests, err = jsonsutil.Unmarshal[[]internal.PredefinedMockedRequest](data)
-		if err != nil {
-			logger.Error(err, fmt.Sprintf("file {%s} cannot be parsed", requestsPredefinedFile))
-		}
+		log.Fatalf("'--storage' parameter {%s} must be one of %v.\n%v", *storage, internal.STORAGES, err)
 	}
 
-	mock := internal.NewMock(requestsDir, predefinedMockedRequests, *logger)
+	mock := internal.NewMockWithStore(store, nil, *logger).WithEviction(*eviction)
 
 	httpServer := server.NewHTTPServer(
 		stringsutil.OrElse(*port, "3333"),
-		server.NewSSL(*ssl, *certificatesDir, *crtFilePath, *keyFilePath),
+		sslConfig,
 		*workingDir,
 		*reqMaxLimit,
 		mock,
 		*logger,
 		resources.Version)
 
+	reloader := server.NewReloader(predefinedSources, *bodyDir, mock, httpServer.Routes, *logger)
+	namespaces := server.NewNamespaces(namespacesStorage, *reqMaxLimit, *logger)
+	httpServer.
+		WithBodyDirectory(*bodyDir).
+		WithReloader(reloader).
+		WithNamespaces(namespaces).
+		WithSessionTTL(*sessionTTL).
+		WithRateLimits(rateLimits).
+		WithAuth(auth).
+		WithOAuth(server.NewOAuth(clients, *oauthTokenTTL)).
+		WithListeners(listeners).
+		WithHTTP2(*enableHTTP2).
+		WithTimeouts(server.Timeouts{ReadHeader: server.READ_HEADER_TIMEOUT, Read: *readTimeout, Write: *writeTimeout, Idle: *idleTimeout})
+
+	if *quotaMocks > 0 || *quotaRate > 0 {
+		httpServer.WithQuotas(server.NewQuotas(*quotaMocks, *quotaRate))
+	}
+
 	fmt.Print(internal.LOGO)
 
 	// load existing requests...
-	var pathToMockId map[string]string
 	if values, err := mock.List(); err == nil && len(values) > 0 {
 		fmt.Printf("\nLoad %d request%s!", len(values), genericsutil.When(values, func(v []internal.MockedRequestLight) bool { return len(v) > 1 }, "s", ""))
-		pathToMockId = make(map[string]string, len(values))
 		for _, b := range values {
-			pathToMockId["/v1"+b.Path] = b.Id
+			httpServer.Routes.Set("/v1"+b.Path, b.Id)
 		}
-		httpServer.PathToMockId = pathToMockId
 	}
 
-	fmt.Printf("\nServer running on port %s[:%s]....\n",
-		genericsutil.When(*ssl, func(arg bool) bool { return arg }, "https", "http"),
-		httpServer.Port)
+	// load predefined requests...
+	if err := reloader.Reload(); err != nil {
+		fmt.Printf("\n%v", err)
+	} else if nb := reloader.Status().Total; nb > 0 {
+		fmt.Printf("\nLoad %d predefined request%s!", nb, genericsutil.When(nb, func(v int) bool { return v > 1 }, "s", ""))
+	}
+	if *watch {
+		go reloader.Watch(server.WATCH_INTERVAL)
+	}
+
+	// load existing namespaces...
+	if nb, err := namespaces.Load(); err != nil {
+		logger.Error(err, "error to load namespaces", "storage", *storage)
+	} else if nb > 0 {
+		fmt.Printf("\nLoad %d namespace%s!", nb, genericsutil.When(nb, func(v int) bool { return v > 1 }, "s", ""))
+	}
+
+	for _, listener := range httpServer.Listeners() {
+		fmt.Printf("\nServer running on port %s....", listener)
+	}
+	fmt.Println()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.Listen()
+	}()
 
-	if err := httpServer.Listen(); err != nil {
+	select {
+	case err := <-errs:
 		log.Fatal("could not open httpServer", err)
+	case <-ctx.Done():
+		fmt.Printf("\nShutting down (%s max)....\n", *shutdownTimeout)
+		logger.Info("shutting down", "timeout", shutdownTimeout)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "error to shut down gracefully")
+		}
+		if closer, ok := store.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// durationOrElse parses the {value} duration or returns {orElse} if it is not valid.
+func durationOrElse(value string, orElse time.Duration) time.Duration {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration
 	}
+	return orElse
 }
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BODY_FILES_DIRECTORY represents the default directory of the body files in the working directory
+var BODY_FILES_DIRECTORY = "files"
+
+// BodyFilePath returns the path of the {bodyFile} relative to the {home} directory of the body files,
+// the file must exist and cannot be outside of the directory.
+func BodyFilePath(home, bodyFile string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(bodyFile)) {
+		return "", fmt.Errorf("bodyFile {%s} is not valid", bodyFile)
+	}
+
+	path := filepath.Join(home, filepath.FromSlash(bodyFile))
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", fmt.Errorf("bodyFile {%s} does not exist", bodyFile)
+	}
+	return path, nil
+}
+
+// CheckBodyDirectory checks that the body files {directory} does not contain any of the {protected} paths
+// (storage, logs, certificates...) and is not inside one of them, the body files are readable by any client.
+func CheckBodyDirectory(directory string, protected []string) error {
+	for _, path := range protected {
+		if path == "" {
+			continue
+		}
+		if within(directory, path) || within(path, directory) {
+			return fmt.Errorf("bodyDir {%s} cannot contain or be inside {%s}", directory, path)
+		}
+	}
+	return nil
+}
+
+// within returns true if the {path} is the {parent} directory or is inside it.
+func within(parent, path string) bool {
+	absParent, err := filepath.Abs(parent)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absParent, absPath)
+	return err == nil && filepath.IsLocal(rel)
+}
@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"go.etcd.io/bbolt"
+)
+
+// backend represents a storage backend which must pass the conformance tests
+type backend struct {
+	name    string
+	store   func(t *testing.T) Store
+	storage func(t *testing.T) Storage
+}
+
+func backends() []backend {
+	openBolt := func(t *testing.T) *bbolt.DB {
+		db, err := bbolt.Open(filepath.Join(t.TempDir(), "mockapic.db"), 0600, nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+
+	return []backend{
+		{
+			name:    STORAGE_FILE,
+			store:   func(t *testing.T) Store { return NewFileStore(t.TempDir()) },
+			storage: func(t *testing.T) Storage { return NewFileStorage(t.TempDir(), *logger) },
+		},
+		{
+			name:    STORAGE_MEMORY,
+			store:   func(t *testing.T) Store { return NewMemoryStore() },
+			storage: func(t *testing.T) Storage { return NewMemoryStorage(*logger) },
+		},
+		{
+			name:    STORAGE_BOLT,
+			store:   func(t *testing.T) Store { return NewBoltStore(openBolt(t), "requests") },
+			storage: func(t *testing.T) Storage { return NewBoltStorage(openBolt(t), *logger) },
+		},
+	}
+}
+
+// TestStoreConformance calls Store.Write, Store.Read, Store.Ids and Store.Delete on each backend,
+// checking for a valid return value.
+func TestStoreConformance(t *testing.T) {
+	for _, backend := range backends() {
+		t.Run(backend.name, func(t *testing.T) {
+			store := backend.store(t)
+
+			if ids, err := store.Ids(); err != nil || len(ids) != 0 {
+				t.Fatalf(`result: {%v} but expected {%v}`, ids, "[]")
+			}
+			if _, err := store.Read("id-1"); err == nil {
+				t.Fatalf(`result: {%v} but expected {%v}`, err, "error")
+			}
+			if err := store.Delete("id-1"); err == nil {
+				t.Fatalf(`result: {%v} but expected {%v}`, err, "error")
+			}
+
+			for _, id := range []string{"id-1", "id-2"} {
+				if err := store.Write(id, []byte("value-"+id)); err != nil {
+					t.Fatal(err.Error())
+				}
+			}
+			if err := store.Write("id-1", []byte("value-1")); err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if value, err := store.Read("id-1"); err != nil || string(value) != "value-1" {
+				t.Fatalf(`result: {%v} but expected {%v}`, string(value), "value-1")
+			}
+
+			if ids, err := store.Ids(); err != nil || len(ids) != 2 || !slicesutil.ContainAll([]string{"id-1", "id-2"}, ids) {
+				t.Fatalf(`result: {%v} but expected {%v}`, ids, []string{"id-1", "id-2"})
+			}
+
+			if err := store.Delete("id-1"); err != nil {
+				t.Fatal(err.Error())
+			}
+			if ids, err := store.Ids(); err != nil || !slicesutil.Equal(ids, []string{"id-2"}) {
+				t.Fatalf(`result: {%v} but expected {%v}`, ids, []string{"id-2"})
+			}
+		})
+	}
+}
+
+// TestMockConformance calls Mock.New, Mock.Add, Mock.Get, Mock.List, Mock.Clean and Mock.Purge on each backend,
+// checking for a valid return value.
+func TestMockConformance(t *testing.T) {
+	for _, backend := range backends() {
+		t.Run(backend.name, func(t *testing.T) {
+			mocker := NewMockWithStore(backend.store(t), nil, *logger)
+
+			created, err := mocker.New(map[string][]string{
+				"status":      {"200"},
+				"contentType": {"application/json"},
+				"charset":     {"UTF-8"},
+				"path":        {"/hello"},
+				"x-language":  {"golang"},
+			}, []byte(`{"hello":"world"}`))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			mock, err := mocker.Get(created.Id)
+			if err != nil || !mock.Equals(*created) {
+				t.Fatalf(`result: {%v} but expected {%v}`, mock, created)
+			}
+
+			if _, err := mocker.Get("does-not-exist"); err == nil {
+				t.Fatalf(`result: {%v} but expected {%v}`, err, "error")
+			}
+
+			added, err := mocker.Add(MockedRequest{
+				MockedRequestLight: MockedRequestLight{
+					CreatedAt: "2000-01-01 00:00:00",
+					ExpiresAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+					MockedRequestHeader: MockedRequestHeader{
+						Status:      204,
+						ContentType: "text/plain",
+						Charset:     "UTF-8",
+					},
+				},
+			})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			values, err := mocker.List()
+			if err != nil || len(values) != 2 || values[0].Id != created.Id || values[1].Id != added.Id {
+				t.Fatalf(`result: {%v} but expected {%v}`, values, []string{created.Id, added.Id})
+			}
+
+			if purged, err := mocker.Purge(time.Now()); err != nil || len(purged) != 1 || purged[0].Id != added.Id {
+				t.Fatalf(`result: {%v} but expected {%v}`, purged, added.Id)
+			}
+
+			mocker.Add(MockedRequest{MockedRequestLight: MockedRequestLight{
+				CreatedAt:           "2000-01-01 00:00:00",
+				MockedRequestHeader: MockedRequestHeader{Status: 204, ContentType: "text/plain", Charset: "UTF-8"}}})
+			if cleaned, err := mocker.Clean(1); err != nil || len(cleaned) != 1 || cleaned[0].CreatedAt != "2000-01-01 00:00:00" {
+				t.Fatalf(`result: {%v} but expected {%v}`, cleaned, 1)
+			}
+			if values, err := mocker.List(); err != nil || len(values) != 1 || values[0].Id != created.Id {
+				t.Fatalf(`result: {%v} but expected {%v}`, values, created.Id)
+			}
+		})
+	}
+}
+
+// TestStorageConformance calls Storage.Open, Storage.Namespaces and Storage.Drop on each backend,
+// checking for a valid return value.
+func TestStorageConformance(t *testing.T) {
+	for _, backend := range backends() {
+		t.Run(backend.name, func(t *testing.T) {
+			storage := backend.storage(t)
+
+			for _, namespace := range []string{"team-b", "team-a"} {
+				mocker, err := storage.Open(namespace)
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+				if _, err := mocker.New(map[string][]string{
+					"status":      {"200"},
+					"contentType": {"text/plain"},
+					"charset":     {"UTF-8"},
+				}, []byte(namespace)); err != nil {
+					t.Fatal(err.Error())
+				}
+			}
+
+			// the mocked requests are kept when the namespace is opened again
+			mocker, _ := storage.Open("team-a")
+			if values, err := mocker.List(); err != nil || len(values) != 1 {
+				t.Fatalf(`result: {%v} but expected {%v}`, values, 1)
+			}
+
+			namespaces, err := storage.Namespaces()
+			if err != nil || !slicesutil.Equal(slicesutil.Sort(namespaces), []string{"team-a", "team-b"}) {
+				t.Fatalf(`result: {%v} but expected {%v}`, namespaces, []string{"team-a", "team-b"})
+			}
+
+			if err := storage.Drop("team-a"); err != nil {
+				t.Fatal(err.Error())
+			}
+			if namespaces, err := storage.Namespaces(); err != nil || !slicesutil.Equal(namespaces, []string{"team-b"}) {
+				t.Fatalf(`result: {%v} but expected {%v}`, namespaces, []string{"team-b"})
+			}
+
+			mocker, _ = storage.Open("team-a")
+			if values, err := mocker.List(); err != nil || len(values) != 0 {
+				t.Fatalf(`result: {%v} but expected {%v}`, values, "[]")
+			}
+		})
+	}
+}
+
+// TestOpenStorage calls OpenStorage,
+// checking for a valid return value.
+func TestOpenStorage(t *testing.T) {
+	for _, kind := range STORAGES {
+		if _, _, err := OpenStorage(kind, t.TempDir(), EVICTION_LRU, *logger); err != nil {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+		}
+	}
+
+	if _, _, err := OpenStorage("sql", t.TempDir(), EVICTION_LRU, *logger); err == nil || err.Error() != "storage {sql} does not exist" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "storage {sql} does not exist")
+	}
+}
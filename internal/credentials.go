@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/go-utils/pkg/stringsutil"
+)
+
+var AUTH_BEARER = "bearer"
+var AUTH_API_KEY = "apiKey"
+var AUTH_HMAC = "hmac"
+var AUTH_JWT = "jwt"
+var AUTH_TYPES = []string{AUTH_BEARER, AUTH_API_KEY, AUTH_HMAC, AUTH_JWT}
+
+// MockedRequestAuth represents the upstream authentication simulated by a mocked request
+type MockedRequestAuth struct {
+	Type string `json:"type"`
+	// Name is the header (or the query parameter) of the API key or of the HMAC signature
+	Name string `json:"name,omitempty"`
+	// In is the location of the API key: {header} (default) or {query}
+	In string `json:"in,omitempty"`
+	// Values are the allowed API keys or the static bearer tokens
+	Values []string `json:"values,omitempty"`
+	// Scope is the scope required on the bearer tokens
+	Scope string `json:"scope,omitempty"`
+	// Secret is the shared secret of the HMAC signature
+	Secret string `json:"secret,omitempty"`
+	// Audience is the {aud} claim required on the JWT
+	Audience string `json:"audience,omitempty"`
+	// Claims are the claims required on the JWT, formatted as {name} or {name}={value}
+	Claims []string `json:"claims,omitempty"`
+	// MockId is the mocked request returned on failure (default {401} or {403})
+	MockId string `json:"mockId,omitempty"`
+}
+
+// Validate checks that the authentication is complete.
+func (a *MockedRequestAuth) Validate() error {
+	switch a.Type {
+	case AUTH_BEARER, AUTH_JWT:
+		return nil
+	case AUTH_API_KEY:
+		if !slicesutil.Exist([]string{"", "header", "query"}, a.In) {
+			return fmt.Errorf("auth in {%s} is not valid", a.In)
+		}
+		if len(a.Values) == 0 {
+			return fmt.Errorf("auth {%s} requires values", a.Type)
+		}
+		return nil
+	case AUTH_HMAC:
+		if a.Secret == "" {
+			return fmt.Errorf("auth {%s} requires a secret", a.Type)
+		}
+		return nil
+	}
+	return fmt.Errorf("auth {%s} is not valid", a.Type)
+}
+
+// CheckAPIKey returns {401} if the API key of the request {r} is missing,
+// {403} if it is not allowed, or {0}.
+func (a *MockedRequestAuth) CheckAPIKey(r *http.Request) int {
+	key := ""
+	if a.In == "query" {
+		key = r.URL.Query().Get(stringsutil.OrElse(a.Name, "api_key"))
+	} else {
+		key = r.Header.Get(stringsutil.OrElse(a.Name, "X-API-Key"))
+	}
+
+	if key == "" {
+		return http.StatusUnauthorized
+	}
+	if !slices.Contains(a.Values, key) {
+		return http.StatusForbidden
+	}
+	return 0
+}
+
+// CheckHMAC returns {401} if the signature of the request {r} is missing,
+// {403} if it is not the hex HMAC-SHA256 of the {body} with the secret, or {0}.
+// The signature may be prefixed by {sha256=}.
+func (a *MockedRequestAuth) CheckHMAC(r *http.Request, body []byte) int {
+	signature := strings.TrimPrefix(r.Header.Get(stringsutil.OrElse(a.Name, "X-Signature")), "sha256=")
+	if signature == "" {
+		return http.StatusUnauthorized
+	}
+
+	mac := hmac.New(sha256.New, []byte(a.Secret))
+	mac.Write(body)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return http.StatusForbidden
+	}
+	return 0
+}
+
+// CheckClaims returns {401} if the JWT {claims} are expired at {now}, not yet valid or not issued for the audience,
+// {403} if a required claim is missing or has another value, or {0}.
+func (a *MockedRequestAuth) CheckClaims(claims map[string]any, now time.Time) int {
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.Unix() >= int64(exp) {
+		return http.StatusUnauthorized
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Unix() < int64(nbf) {
+		return http.StatusUnauthorized
+	}
+	if a.Audience != "" && !claimContains(claims["aud"], a.Audience) {
+		return http.StatusUnauthorized
+	}
+
+	for _, claim := range a.Claims {
+		name, value, hasValue := strings.Cut(claim, "=")
+		if v, ok := claims[name]; !ok || (hasValue && !claimContains(v, value)) {
+			return http.StatusForbidden
+		}
+	}
+	return 0
+}
+
+// claimContains returns true if the {claim} (a value or a list of values) contains the {value}.
+func claimContains(claim any, value string) bool {
+	if values, ok := claim.([]any); ok {
+		return slicesutil.ExistT(values, func(v any) bool { return fmt.Sprint(v) == value })
+	}
+	return claim != nil && fmt.Sprint(claim) == value
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestMockedRequestAuthValidate calls MockedRequestAuth.Validate,
+// checking for a valid return value.
+func TestMockedRequestAuthValidate(t *testing.T) {
+	for _, value := range []struct {
+		auth     MockedRequestAuth
+		expected string
+	}{
+		{MockedRequestAuth{Type: AUTH_BEARER}, ""},
+		{MockedRequestAuth{Type: AUTH_API_KEY}, "auth {apiKey} requires values"},
+		{MockedRequestAuth{Type: AUTH_API_KEY, In: "cookie"}, "auth in {cookie} is not valid"},
+		{MockedRequestAuth{Type: AUTH_HMAC}, "auth {hmac} requires a secret"},
+		{MockedRequestAuth{Type: AUTH_HMAC, Secret: "secret"}, ""},
+		{MockedRequestAuth{Type: "digest"}, "auth {digest} is not valid"},
+	} {
+		if err := value.auth.Validate(); (err == nil && value.expected != "") || (err != nil && err.Error() != value.expected) {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, value.expected)
+		}
+	}
+
+	mock, err := NewMockWithStore(NewMemoryStore(), nil, *logger).New(map[string][]string{
+		"status": {"200"}, "contentType": {"text/plain"}, "charset": {"UTF-8"},
+		"auth": {"apiKey"}, "authIn": {"query"}, "authName": {"key"}, "authValues": {"key-1,key-2"}, "authMockId": {"401-mock"},
+	}, nil)
+	if err != nil || mock.Auth.Type != AUTH_API_KEY || mock.Auth.In != "query" || mock.Auth.Name != "key" ||
+		len(mock.Auth.Values) != 2 || mock.Auth.MockId != "401-mock" || len(mock.Headers) != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, mock, "apiKey")
+	}
+}
+
+// TestMockedRequestAuthCheck calls MockedRequestAuth.CheckAPIKey and MockedRequestAuth.CheckHMAC,
+// checking for a valid return value.
+func TestMockedRequestAuthCheck(t *testing.T) {
+	request := func(url string, headers map[string]string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, url, nil)
+		for key, value := range headers {
+			r.Header.Set(key, value)
+		}
+		return r
+	}
+
+	apiKey := MockedRequestAuth{Type: AUTH_API_KEY, Values: []string{"key-1"}}
+	if r := apiKey.CheckAPIKey(request("/", nil)); r != http.StatusUnauthorized {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, http.StatusUnauthorized)
+	}
+	if r := apiKey.CheckAPIKey(request("/", map[string]string{"X-API-Key": "key-2"})); r != http.StatusForbidden {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, http.StatusForbidden)
+	}
+	if r := apiKey.CheckAPIKey(request("/", map[string]string{"X-API-Key": "KEY-1"})); r != http.StatusForbidden {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, http.StatusForbidden)
+	}
+	if r := apiKey.CheckAPIKey(request("/", map[string]string{"X-API-Key": "key-1"})); r != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, 0)
+	}
+	apiKey.In = "query"
+	if r := apiKey.CheckAPIKey(request("/?api_key=key-1", nil)); r != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, 0)
+	}
+
+	body := []byte(`{"id":1}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	signed := MockedRequestAuth{Type: AUTH_HMAC, Secret: "secret"}
+	if r := signed.CheckHMAC(request("/", nil), body); r != http.StatusUnauthorized {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, http.StatusUnauthorized)
+	}
+	if r := signed.CheckHMAC(request("/", map[string]string{"X-Signature": signature}), []byte(`{"id":2}`)); r != http.StatusForbidden {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, http.StatusForbidden)
+	}
+	if r := signed.CheckHMAC(request("/", map[string]string{"X-Signature": "sha256=" + signature}), body); r != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, 0)
+	}
+}
+
+// TestMockedRequestAuthCheckClaims calls MockedRequestAuth.CheckClaims,
+// checking for a valid return value.
+func TestMockedRequestAuthCheckClaims(t *testing.T) {
+	now := time.Now()
+	exp := float64(now.Add(time.Minute).Unix())
+	auth := MockedRequestAuth{Type: AUTH_JWT, Audience: "orders", Claims: []string{"sub", "roles=admin"}}
+
+	values := []struct {
+		claims   map[string]any
+		expected int
+	}{
+		{map[string]any{"aud": "orders", "sub": "user", "roles": []any{"user", "admin"}}, http.StatusUnauthorized},
+		{map[string]any{"exp": float64(now.Unix()), "aud": "orders", "sub": "user", "roles": "admin"}, http.StatusUnauthorized},
+		{map[string]any{"exp": exp, "nbf": exp, "aud": "orders", "sub": "user", "roles": "admin"}, http.StatusUnauthorized},
+		{map[string]any{"exp": exp, "aud": "payments", "sub": "user", "roles": "admin"}, http.StatusUnauthorized},
+		{map[string]any{"exp": exp, "aud": []any{"orders"}, "roles": "admin"}, http.StatusForbidden},
+		{map[string]any{"exp": exp, "aud": "orders", "sub": "user", "roles": []any{"user"}}, http.StatusForbidden},
+		{map[string]any{"exp": exp, "aud": []any{"payments", "orders"}, "sub": "user", "roles": []any{"user", "admin"}}, 0},
+	}
+	for _, value := range values {
+		if r := auth.CheckClaims(value.claims, now); r != value.expected {
+			t.Fatalf(`result: %v => {%v} but expected {%v}`, value.claims, r, value.expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+)
+
+const (
+	EVICTION_CREATED = "created"
+	EVICTION_LRU     = "lru"
+	EVICTION_LFU     = "lfu"
+)
+
+// EVICTION_POLICIES represents the policies to choose the mocked requests removed over the max limit
+var EVICTION_POLICIES = []string{EVICTION_CREATED, EVICTION_LRU, EVICTION_LFU}
+
+// access represents the usage of a mocked request since the start
+type access struct {
+	lastAccessAt time.Time
+	hits         int
+}
+
+// usage records the accesses to the mocked requests
+type usage struct {
+	mu     sync.Mutex
+	values map[string]access
+}
+
+func newUsage() *usage {
+	return &usage{values: map[string]access{}}
+}
+
+// hit records an access to the mocked request {mockId} at {now}.
+func (u *usage) hit(mockId string, now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	value := u.values[mockId]
+	value.lastAccessAt = now
+	value.hits = value.hits + 1
+	u.values[mockId] = value
+}
+
+// get returns the usage of the mocked request {mockId}.
+func (u *usage) get(mockId string) access {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.values[mockId]
+}
+
+// remove forgets the usage of the mocked request {mockId}.
+func (u *usage) remove(mockId string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.values, mockId)
+}
+
+// lastAccessAt returns the last access time of the mocked request or its creation time if it has never been called.
+func (u *usage) lastAccessAt(mock MockedRequestLight) time.Time {
+	if value := u.get(mock.Id); !value.lastAccessAt.IsZero() {
+		return value.lastAccessAt
+	}
+	createdAt, _ := time.ParseInLocation("2006-01-02 15:04:05", mock.CreatedAt, time.Local)
+	return createdAt
+}
+
+// sort returns the mocked requests {values} sorted by the {policy}, the ones to keep first.
+func (u *usage) sort(values []MockedRequestLight, policy string) []MockedRequestLight {
+	switch policy {
+	case EVICTION_LRU:
+		return slicesutil.SortTByTime(values, func(m1, m2 MockedRequestLight) (time.Time, time.Time) {
+			return u.lastAccessAt(m2), u.lastAccessAt(m1)
+		})
+	case EVICTION_LFU:
+		return slicesutil.SortT(u.sort(values, EVICTION_LRU), func(m1, m2 MockedRequestLight) (int, int) {
+			return u.get(m2.Id).hits, u.get(m1.Id).hits
+		})
+	default:
+		return values
+	}
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+)
+
+// TestCleanWithEviction calls Mock.Touch and Mock.Clean with the eviction policies,
+// checking for a valid return value.
+func TestCleanWithEviction(t *testing.T) {
+	tests := map[string][]string{
+		EVICTION_CREATED: {"pinned", "newest"},
+		EVICTION_LRU:     {"pinned", "oldest"},
+		EVICTION_LFU:     {"pinned", "middle"},
+	}
+
+	for policy, expected := range tests {
+		mocker := NewMockWithStore(NewMemoryStore(), nil, *logger).WithEviction(policy)
+		for id, createdAt := range map[string]string{
+			"pinned": "2024-01-01 00:00:00",
+			"oldest": "2024-01-02 00:00:00",
+			"middle": "2024-01-03 00:00:00",
+			"newest": "2024-01-04 00:00:00",
+		} {
+			mocker.Add(MockedRequest{
+				MockedRequestLight: MockedRequestLight{
+					Id:        id,
+					CreatedAt: createdAt,
+					Pinned:    id == "pinned",
+					MockedRequestHeader: MockedRequestHeader{
+						Status:      200,
+						ContentType: "text/plain",
+						Charset:     "UTF-8",
+					},
+				},
+			})
+		}
+
+		// {middle} is the most frequently used and {oldest} the most recently used
+		mocker.Touch("middle")
+		mocker.Touch("middle")
+		mocker.Touch("oldest")
+
+		// the management reads are not recorded
+		mocker.Get("newest")
+		mocker.Get("newest")
+		mocker.Get("newest")
+
+		cleaned, err := mocker.Clean(2)
+		if err != nil || len(cleaned) != 2 {
+			t.Fatalf(`%s result: {%v} but expected {%v}`, policy, cleaned, 2)
+		}
+
+		values, _ := mocker.List()
+		ids := slicesutil.TransformT(values, func(mrl MockedRequestLight) (*string, error) { return &mrl.Id, nil })
+		if !slicesutil.ContainAll(ids, expected) || len(ids) != len(expected) {
+			t.Fatalf(`%s result: {%v} but expected {%v}`, policy, ids, expected)
+		}
+	}
+}
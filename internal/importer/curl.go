@@ -0,0 +1,277 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// CurlCommand represents a curl command line and the response to mock
+type CurlCommand struct {
+	Command string `json:"command"`
+	// Response is the raw http response (`curl -i` output) or only the body
+	Response    string            `json:"response"`
+	Status      int               `json:"status"`
+	ContentType string            `json:"contentType"`
+	Headers     map[string]string `json:"headers"`
+}
+
+// request headers which are not used to match the incoming requests
+var ignoredRequestHeaders = []string{"Accept-Encoding", "Authorization", "Connection", "Content-Length", "Cookie", "Host", "User-Agent"}
+
+// curl options which expect an argument not used by the conversion
+var curlOptionsWithArg = []string{
+	"-A", "--user-agent", "-e", "--referer", "-o", "--output", "-m", "--max-time", "--connect-timeout",
+	"--retry", "-w", "--write-out", "--cacert", "-E", "--cert", "--key", "-x", "--proxy", "-c", "--cookie-jar",
+	"-r", "--range", "-T", "--upload-file", "--resolve", "--limit-rate", "-K", "--config",
+}
+
+// Curl converts the curl command line and the pasted response to a mocked request.
+func Curl(data []byte) (*Result, error) {
+	var command CurlCommand
+	if err := json.Unmarshal(data, &command); err != nil {
+		return nil, err
+	}
+	return command.ToResult()
+}
+
+// ToResult converts the curl command to a mocked request.
+func (c CurlCommand) ToResult() (*Result, error) {
+	args, err := splitCommandLine(c.Command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.New("command must start with {curl}")
+	}
+
+	result := &Result{}
+	source := "curl"
+
+	method := ""
+	rawURL := ""
+	get := false
+	data := []string{}
+	headers := map[string]string{}
+
+	for i := 1; i < len(args); i++ {
+		name, value, hasValue := args[i], "", false
+		if strings.HasPrefix(name, "--") {
+			if n, v, found := strings.Cut(name, "="); found {
+				name, value, hasValue = n, v, true
+			}
+		} else if strings.HasPrefix(name, "-") && len(name) > 2 && strings.ContainsAny(name[1:2], "XHdbuF") {
+			name, value, hasValue = name[:2], name[2:], true
+		}
+
+		nextValue := func() string {
+			if hasValue {
+				return value
+			}
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+
+		switch {
+		case name == "-X" || name == "--request":
+			method = strings.ToUpper(nextValue())
+		case name == "-H" || name == "--header":
+			if key, v, found := strings.Cut(nextValue(), ":"); found {
+				headers[strings.TrimSpace(key)] = strings.TrimSpace(v)
+			}
+		case name == "-d" || strings.HasPrefix(name, "--data"):
+			data = append(data, nextValue())
+		case name == "-F" || name == "--form":
+			nextValue()
+			result.unsupported(source, "form data matching is not supported")
+		case name == "-G" || name == "--get":
+			get = true
+		case name == "-I" || name == "--head":
+			method = http.MethodHead
+		case name == "-u" || name == "--user":
+			nextValue()
+			result.unsupported(source, "user credentials are not supported")
+		case name == "-b" || name == "--cookie":
+			nextValue()
+			result.unsupported(source, "request cookies matching is not supported")
+		case name == "--url":
+			rawURL = nextValue()
+		case slicesutil.Exist(curlOptionsWithArg, name):
+			nextValue()
+		case strings.HasPrefix(name, "-"):
+			// option without argument (-s, -L, -k, --compressed...)
+		default:
+			if rawURL == "" {
+				rawURL = name
+			}
+		}
+	}
+
+	if rawURL == "" {
+		return nil, errors.New("no url found in the curl command")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	if len(data) > 0 {
+		if get {
+			for _, d := range data {
+				values, _ := url.ParseQuery(d)
+				for key, v := range values {
+					query[key] = append(query[key], v...)
+				}
+			}
+		} else {
+			if method == "" {
+				method = http.MethodPost
+			}
+			result.unsupported(source, "request body matching is not supported")
+		}
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	matcher := &internal.MockedRequestMatcher{Method: method}
+	if len(query) > 0 {
+		matcher.Query = map[string]string{}
+		for key, values := range query {
+			if len(values) > 1 {
+				result.unsupported(source, "query parameter {%s} with several values, only the first one is matched", key)
+			}
+			matcher.Query[key] = values[0]
+		}
+	}
+	matcher.Headers = requestHeaders(source, headers, result)
+
+	mock, err := c.response(u.Path)
+	if err != nil {
+		return nil, err
+	}
+	mock.Request = matcher
+	result.Mocks = append(result.Mocks, *mock)
+
+	return result, nil
+}
+
+// response builds the mocked request from the raw http response or from the provided fields.
+func (c CurlCommand) response(path string) (*internal.MockedRequest, error) {
+	status := c.Status
+	headers := map[string]string{}
+	body := []byte(c.Response)
+
+	if strings.HasPrefix(c.Response, "HTTP/") {
+		raw := c.Response
+		if !strings.Contains(raw, "\r\n") {
+			raw = strings.ReplaceAll(raw, "\n", "\r\n")
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(strings.NewReader(raw)), nil)
+		if err != nil {
+			return nil, fmt.Errorf("response cannot be parsed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, err = io.ReadAll(resp.Body)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("response body cannot be read: %v", err)
+		}
+		if status == 0 {
+			status = resp.StatusCode
+		}
+		for key := range resp.Header {
+			headers[key] = resp.Header.Get(key)
+		}
+	}
+
+	for key, value := range c.Headers {
+		headers[key] = value
+	}
+	if c.ContentType != "" {
+		headers["Content-Type"] = c.ContentType
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	mock := newMockedRequest(status, path, headers, body)
+	return &mock, nil
+}
+
+// splitCommandLine splits the command line in arguments like a POSIX shell
+// (quotes, escaped characters and line continuations).
+func splitCommandLine(command string) ([]string, error) {
+	args := []string{}
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else if c == '\\' && i+1 < len(runes) && strings.ContainsRune("\\\"$`\n", runes[i+1]) {
+				i++
+				if runes[i] != '\n' {
+					current.WriteRune(runes[i])
+				}
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\n' || runes[i] == '\r' {
+					continue
+				}
+				current.WriteRune(runes[i])
+				inArg = true
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quoted string in the command")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
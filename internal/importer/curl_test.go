@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestCurl calls Curl([]byte),
+// checking for a valid return value.
+func TestCurl(t *testing.T) {
+	data := `{
+		"command": "curl -s -X POST 'https://api.com/v1/rates?base=EUR&symbols=USD' \\\n -H 'Accept: application/json' -H \"x-api-version: 2\" -H 'Authorization: Bearer xxx' --data-raw '{\"amount\":10}'",
+		"response": "HTTP/1.1 201 Created\nContent-Type: application/json; charset=utf-8\nx-request-id: 42\n\n{\"USD\":11}"
+	}`
+
+	r, err := Curl([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := internal.MockedRequest{
+		MockedRequestLight: internal.MockedRequestLight{
+			MockedRequestHeader: internal.MockedRequestHeader{
+				Status:      201,
+				ContentType: "application/json",
+				Charset:     "UTF-8",
+				Headers:     map[string]string{"X-Request-Id": "42"},
+				Path:        "/v1/rates",
+				Request: &internal.MockedRequestMatcher{
+					Method:  "POST",
+					Query:   map[string]string{"base": "EUR", "symbols": "USD"},
+					Headers: map[string]string{"Accept": "application/json", "x-api-version": "2"},
+				},
+			},
+		},
+		Body64: []byte(`{"USD":11}`),
+	}
+
+	if len(r.Mocks) != 1 || !r.Mocks[0].Equals(expected) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r.Mocks, expected)
+	}
+
+	for _, unsupported := range []string{
+		"curl: request header {Authorization} is ignored",
+		"curl: request body matching is not supported",
+	} {
+		if !slicesutil.Exist(r.Unsupported, unsupported) {
+			t.Fatalf(`result: {%v} but expected {%v}`, r.Unsupported, unsupported)
+		}
+	}
+}
+
+// TestCurlWithStatusAndBody calls Curl([]byte),
+// checking for a valid return value.
+func TestCurlWithStatusAndBody(t *testing.T) {
+	data := `{
+		"command": "curl -G --url localhost:3333/search -d q=golang -XGET",
+		"status": 404,
+		"contentType": "text/plain",
+		"response": "Not Found"
+	}`
+
+	r, err := Curl([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := internal.MockedRequest{
+		MockedRequestLight: internal.MockedRequestLight{
+			MockedRequestHeader: internal.MockedRequestHeader{
+				Status:      404,
+				ContentType: "text/plain",
+				Charset:     "UTF-8",
+				Headers:     map[string]string{},
+				Path:        "/search",
+				Request:     &internal.MockedRequestMatcher{Method: "GET", Query: map[string]string{"q": "golang"}},
+			},
+		},
+		Body64: []byte("Not Found"),
+	}
+
+	if len(r.Mocks) != 1 || !r.Mocks[0].Equals(expected) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r.Mocks, expected)
+	}
+}
+
+// TestCurlWithBadCommand calls Curl([]byte),
+// checking for a valid return value.
+func TestCurlWithBadCommand(t *testing.T) {
+	for data, expected := range map[string]string{
+		`{"command": "wget https://api.com"}`:                     "command must start with {curl}",
+		`{"command": "curl -X GET"}`:                              "no url found in the curl command",
+		`{"command": "curl 'https://api.com"}`:                    "unterminated quoted string in the command",
+		`{"command": "curl api.com", "response": "HTTP/1.1 abc"}`: "response cannot be parsed: malformed HTTP status code \"abc\"",
+	} {
+		if _, err := Curl([]byte(data)); err == nil || err.Error() != expected {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, expected)
+		}
+	}
+
+	if r, err := Curl([]byte("bad data")); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, r)
+	}
+}
+
+// TestSplitCommandLine calls splitCommandLine(string),
+// checking for a valid return value.
+func TestSplitCommandLine(t *testing.T) {
+	r, err := splitCommandLine("curl -H 'a: b' \"c \\\"d\\\"\" e\\ f \\\n g")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []string{"curl", "-H", "a: b", `c "d"`, "e f", "g"}
+	if !slicesutil.Equal(r, expected) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, expected)
+	}
+}
@@ -0,0 +1,108 @@
+package importer
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/mockapic/internal"
+	"github.com/joakim-ribier/mockapic/pkg"
+)
+
+var FORMATS = []string{"curl", "mockapic", "postman", "wiremock"}
+
+// headers computed by the server which must not be replayed from a recorded response
+var skippedHeaders = []string{"Connection", "Content-Length", "Keep-Alive", "Transfer-Encoding"}
+
+// Result represents the mocked requests converted from an external format
+// and the list of the features which cannot be mapped to {Mockapic}.
+type Result struct {
+	Mocks       []internal.MockedRequest
+	Unsupported []string
+}
+
+func (r *Result) unsupported(source, format string, args ...any) {
+	r.Unsupported = append(r.Unsupported, source+": "+fmt.Sprintf(format, args...))
+}
+
+// Import converts the {data} from the {format} to mocked requests.
+func Import(format string, data []byte) (*Result, error) {
+	switch format {
+	case "curl":
+		return Curl(data)
+	case "mockapic":
+		return Mockapic(data)
+	case "postman":
+		return Postman(data)
+	case "wiremock":
+		return WireMock(data)
+	}
+	return nil, fmt.Errorf("format {%s} does not exist", format)
+}
+
+// newMockedRequest creates a mocked request from the response definition,
+// the {Content-Type} header is split on the content type and the charset fields.
+func newMockedRequest(status int, path string, headers map[string]string, body []byte) internal.MockedRequest {
+	mock := internal.MockedRequest{
+		MockedRequestLight: internal.MockedRequestLight{
+			MockedRequestHeader: internal.MockedRequestHeader{
+				Status:      status,
+				ContentType: "text/plain",
+				Charset:     "UTF-8",
+				Headers:     map[string]string{},
+				Path:        path,
+			},
+		},
+		Body64: body,
+	}
+
+	for key, value := range headers {
+		if slicesutil.ExistT(skippedHeaders, func(h string) bool { return strings.EqualFold(h, key) }) {
+			continue
+		}
+		if !strings.EqualFold(key, "Content-Type") {
+			mock.Headers[key] = value
+			continue
+		}
+
+		mediaType, params, err := mime.ParseMediaType(value)
+		if err != nil || !slicesutil.Exist(pkg.CONTENT_TYPES, mediaType) {
+			// unknown content type, the header overrides the default one on the response
+			mock.Headers[key] = value
+			continue
+		}
+		mock.ContentType = mediaType
+		if charset := slicesutil.FindT(pkg.CHARSET, func(c string) bool { return strings.EqualFold(c, params["charset"]) }); charset != nil {
+			mock.Charset = *charset
+		}
+	}
+
+	return mock
+}
+
+// requestHeaders returns the request headers to match,
+// the headers which depend on the client or on the environment are ignored.
+func requestHeaders(source string, headers map[string]string, result *Result) map[string]string {
+	values := map[string]string{}
+	for key, value := range headers {
+		if slicesutil.ExistT(ignoredRequestHeaders, func(h string) bool { return strings.EqualFold(h, key) }) {
+			result.unsupported(source, "request header {%s} is ignored", key)
+			continue
+		}
+		values[key] = value
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// Mockapic converts a list of {Mockapic} mocked requests formatted in JSON or in YAML
+// (the format of the predefined files and of the export) to mocked requests.
+func Mockapic(data []byte) (*Result, error) {
+	format := internal.FORMAT_YAML
+	if json.Valid(data) {
+		format = internal.FORMAT_JSON
+	}
+
+	values, err := internal.UnmarshalPredefined(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, errors.New("no mocked request found")
+	}
+
+	result := &Result{}
+	for _, value := range values {
+		// the identifier, the creation date and the owner are not imported:
+		// a new mocked request is always created and never replaces an existing one
+		mock := value.ToMockedRequest()
+		mock.Id, mock.CreatedAt, mock.TokenHash, mock.Source = "", "", "", ""
+		result.Mocks = append(result.Mocks, *mock)
+	}
+	return result, nil
+}
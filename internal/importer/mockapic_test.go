@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestMockapic calls Mockapic([]byte),
+// checking for a valid return value.
+func TestMockapic(t *testing.T) {
+	expected := internal.MockedRequest{
+		MockedRequestLight: internal.MockedRequestLight{
+			MockedRequestHeader: internal.MockedRequestHeader{
+				Status:      200,
+				ContentType: "text/plain",
+				Charset:     "UTF-8",
+				Path:        "/hello",
+			},
+		},
+		Body64: []byte("Hello\nWorld\n"),
+	}
+
+	for _, data := range []string{
+		`[{"id":"id-1","createdAt":"2024-01-01 00:00:00","tokenHash":"hash","status":200,"contentType":"text/plain","charset":"UTF-8","path":"/hello","source":"mockapic.json","body":"Hello\nWorld\n"}]`,
+		"- id: id-1\n  status: 200\n  contentType: text/plain\n  charset: UTF-8\n  path: /hello\n  source: mockapic.yaml\n  body: |\n    Hello\n    World\n",
+	} {
+		r, err := Mockapic([]byte(data))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if len(r.Mocks) != 1 || !r.Mocks[0].Equals(expected) || r.Mocks[0].Source != "" || r.Mocks[0].Body != "" ||
+			r.Mocks[0].Id != "" || r.Mocks[0].CreatedAt != "" || r.Mocks[0].TokenHash != "" {
+			t.Fatalf(`result: {%v} but expected {%v}`, r.Mocks, expected)
+		}
+	}
+
+	if _, err := Import("mockapic", []byte("[]")); err == nil || err.Error() != "no mocked request found" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "no mocked request found")
+	}
+}
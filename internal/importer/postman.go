@@ -0,0 +1,203 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/joakim-ribier/mockapic/internal"
+	"github.com/joakim-ribier/mockapic/pkg"
+)
+
+type postmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+type postmanURL struct {
+	Raw   string            `json:"raw"`
+	Path  []string          `json:"path"`
+	Query []postmanKeyValue `json:"query"`
+}
+
+// UnmarshalJSON accepts both the string and the object url definitions.
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+
+	type alias postmanURL
+	var value alias
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*u = postmanURL(value)
+	return nil
+}
+
+// path returns the path of the url without the host and the query string.
+func (u postmanURL) path() string {
+	if len(u.Path) > 0 {
+		return "/" + strings.Join(u.Path, "/")
+	}
+
+	raw := u.Raw
+	if i := strings.Index(raw, "://"); i > -1 {
+		raw = raw[i+3:]
+	}
+	if i := strings.IndexAny(raw, "?#"); i > -1 {
+		raw = raw[:i]
+	}
+	if i := strings.Index(raw, "/"); i > -1 && !strings.HasPrefix(raw, "/") {
+		return raw[i:]
+	}
+	if strings.HasPrefix(raw, "/") {
+		return raw
+	}
+	return ""
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	URL    *postmanURL       `json:"url"`
+	Body   json.RawMessage   `json:"body"`
+}
+
+type postmanResponse struct {
+	Name            string            `json:"name"`
+	OriginalRequest *postmanRequest   `json:"originalRequest"`
+	Code            int               `json:"code"`
+	Header          []postmanKeyValue `json:"header"`
+	Cookie          []any             `json:"cookie"`
+	Body            string            `json:"body"`
+}
+
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item"`
+	Request  *postmanRequest   `json:"request"`
+	Response []postmanResponse `json:"response"`
+}
+
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item []postmanItem `json:"item"`
+}
+
+// Postman converts the saved examples of a Postman Collection v2.1 to mocked requests.
+func Postman(data []byte) (*Result, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, err
+	}
+	if collection.Item == nil {
+		return nil, errors.New("no postman collection item found")
+	}
+
+	result := &Result{}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.1") {
+		result.unsupported("collection["+collection.Info.Name+"]", "schema {%s} is not supported, expected v2.1", collection.Info.Schema)
+	}
+
+	type imported struct {
+		source  string
+		request *internal.MockedRequestMatcher
+	}
+	paths := map[string][]imported{}
+	var walk func(items []postmanItem, parent string)
+	walk = func(items []postmanItem, parent string) {
+		for _, item := range items {
+			name := strings.TrimPrefix(parent+"/"+item.Name, "/")
+			if len(item.Item) > 0 {
+				walk(item.Item, name)
+				continue
+			}
+			if len(item.Response) == 0 {
+				result.unsupported("item["+name+"]", "no saved example found, item ignored")
+				continue
+			}
+			for _, response := range item.Response {
+				source := "item[" + name + "/" + response.Name + "]"
+				if mock := response.toMockedRequest(source, item.Request, result); mock != nil {
+					// the examples of the same path are all served if they match different requests
+					if mock.Path != "" {
+						for _, previous := range paths[mock.Path] {
+							if previous.request.Equal(mock.Request) {
+								result.unsupported(source, "path {%s} already used by {%s}, the last imported one is served", mock.Path, previous.source)
+							}
+						}
+						paths[mock.Path] = append(paths[mock.Path], imported{source: source, request: mock.Request})
+					}
+					result.Mocks = append(result.Mocks, *mock)
+				}
+			}
+		}
+	}
+	walk(collection.Item, "")
+
+	return result, nil
+}
+
+func (r postmanResponse) toMockedRequest(source string, itemRequest *postmanRequest, result *Result) *internal.MockedRequest {
+	status := r.Code
+	if status == 0 {
+		status = 200
+	}
+	if _, is := pkg.HTTP_CODES[status]; !is {
+		result.unsupported(source, "status {%d} is not supported, example ignored", status)
+		return nil
+	}
+
+	request := r.OriginalRequest
+	if request == nil {
+		request = itemRequest
+	}
+
+	path := ""
+	var matcher *internal.MockedRequestMatcher
+	if request != nil {
+		matcher = &internal.MockedRequestMatcher{Method: strings.ToUpper(request.Method)}
+		if request.URL != nil {
+			path = request.URL.path()
+			if strings.Contains(path, "{{") || strings.Contains(path, "/:") {
+				result.unsupported(source, "path variables {%s} are not supported, the path is used as is", path)
+			}
+			for _, query := range request.URL.Query {
+				if !query.Disabled && query.Key != "" {
+					matcher.Query = setValue(matcher.Query, query.Key, query.Value)
+				}
+			}
+		}
+		headers := map[string]string{}
+		for _, header := range request.Header {
+			if !header.Disabled && header.Key != "" {
+				headers[header.Key] = header.Value
+			}
+		}
+		matcher.Headers = requestHeaders(source, headers, result)
+		if len(request.Body) > 0 && string(request.Body) != "null" && string(request.Body) != "{}" {
+			result.unsupported(source, "request body matching is not supported")
+		}
+	}
+	if len(r.Cookie) > 0 {
+		result.unsupported(source, "response cookies are not supported")
+	}
+
+	headers := map[string]string{}
+	for _, header := range r.Header {
+		if !header.Disabled && header.Key != "" {
+			headers[header.Key] = header.Value
+		}
+	}
+
+	mock := newMockedRequest(status, path, headers, []byte(r.Body))
+	mock.Request = matcher
+	return &mock
+}
@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestPostman calls Postman([]byte),
+// checking for a valid return value.
+func TestPostman(t *testing.T) {
+	data := `{
+		"info": {"name": "currencies", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+		"item": [
+			{
+				"name": "rates",
+				"item": [
+					{
+						"name": "latest",
+						"request": {"method": "GET", "url": "{{baseUrl}}/latest"},
+						"response": [
+							{
+								"name": "success",
+								"originalRequest": {
+									"method": "GET",
+									"header": [{"key": "Authorization", "value": "Bearer {{token}}"}],
+									"url": {"raw": "{{baseUrl}}/latest?base=EUR", "host": ["{{baseUrl}}"], "path": ["latest"], "query": [{"key": "base", "value": "EUR"}]}
+								},
+								"code": 200,
+								"header": [
+									{"key": "Content-Type", "value": "application/json; charset=utf-8"},
+									{"key": "x-domain", "value": "github.com"},
+									{"key": "x-disabled", "value": "true", "disabled": true}
+								],
+								"body": "{\"EUR\":1}"
+							},
+							{
+								"name": "unavailable",
+								"code": 503,
+								"body": "Service Unavailable"
+							},
+							{
+								"name": "error",
+								"code": 500,
+								"body": "Internal Server Error"
+							}
+						]
+					}
+				]
+			},
+			{
+				"name": "no example",
+				"request": {"method": "GET", "url": "https://api.com/no-example"}
+			}
+		]
+	}`
+
+	r, err := Postman([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []internal.MockedRequest{
+		{
+			MockedRequestLight: internal.MockedRequestLight{
+				MockedRequestHeader: internal.MockedRequestHeader{
+					Status:      200,
+					ContentType: "application/json",
+					Charset:     "UTF-8",
+					Headers:     map[string]string{"x-domain": "github.com"},
+					Path:        "/latest",
+					Request:     &internal.MockedRequestMatcher{Method: "GET", Query: map[string]string{"base": "EUR"}},
+				},
+			},
+			Body64: []byte(`{"EUR":1}`),
+		},
+		{
+			MockedRequestLight: internal.MockedRequestLight{
+				MockedRequestHeader: internal.MockedRequestHeader{
+					Status:      503,
+					ContentType: "text/plain",
+					Charset:     "UTF-8",
+					Headers:     map[string]string{},
+					Path:        "/latest",
+					Request:     &internal.MockedRequestMatcher{Method: "GET"},
+				},
+			},
+			Body64: []byte("Service Unavailable"),
+		},
+		{
+			MockedRequestLight: internal.MockedRequestLight{
+				MockedRequestHeader: internal.MockedRequestHeader{
+					Status:      500,
+					ContentType: "text/plain",
+					Charset:     "UTF-8",
+					Headers:     map[string]string{},
+					Path:        "/latest",
+					Request:     &internal.MockedRequestMatcher{Method: "GET"},
+				},
+			},
+			Body64: []byte("Internal Server Error"),
+		},
+	}
+
+	if len(r.Mocks) != len(expected) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r.Mocks, expected)
+	}
+	for i, mock := range r.Mocks {
+		if !mock.Equals(expected[i]) {
+			t.Fatalf(`result: {%v} but expected {%v}`, mock, expected[i])
+		}
+	}
+
+	for _, unsupported := range []string{
+		"item[rates/latest/success]: request header {Authorization} is ignored",
+		"item[rates/latest/error]: path {/latest} already used by {item[rates/latest/unavailable]}, the last imported one is served",
+		"item[no example]: no saved example found, item ignored",
+	} {
+		if !slicesutil.Exist(r.Unsupported, unsupported) {
+			t.Fatalf(`result: {%v} but expected {%v}`, r.Unsupported, unsupported)
+		}
+	}
+
+	// the examples which match different requests are all served
+	if len(r.Unsupported) != 3 {
+		t.Fatalf(`result: {%v} but expected {%v}`, r.Unsupported, 3)
+	}
+}
+
+// TestPostmanURLPath calls postmanURL.path(),
+// checking for a valid return value.
+func TestPostmanURLPath(t *testing.T) {
+	for raw, expected := range map[string]string{
+		"https://api.com/v1/rates?base=EUR": "/v1/rates",
+		"{{baseUrl}}/rates":                 "/rates",
+		"/rates#anchor":                     "/rates",
+		"api.com":                           "",
+	} {
+		if r := (postmanURL{Raw: raw}).path(); r != expected {
+			t.Fatalf(`result: {%v} but expected {%v}`, r, expected)
+		}
+	}
+}
+
+// TestPostmanWithBadData calls Postman([]byte),
+// checking for a valid return value.
+func TestPostmanWithBadData(t *testing.T) {
+	if r, err := Postman([]byte("bad data")); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, r)
+	}
+
+	if r, err := Postman([]byte(`{"info": {}}`)); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, r)
+	}
+}
@@ -0,0 +1,241 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joakim-ribier/mockapic/internal"
+	"github.com/joakim-ribier/mockapic/pkg"
+)
+
+type wireMockRequest struct {
+	Method               string         `json:"method"`
+	URL                  string         `json:"url"`
+	URLPath              string         `json:"urlPath"`
+	URLPattern           string         `json:"urlPattern"`
+	URLPathPattern       string         `json:"urlPathPattern"`
+	QueryParameters      map[string]any `json:"queryParameters"`
+	Headers              map[string]any `json:"headers"`
+	Cookies              map[string]any `json:"cookies"`
+	BodyPatterns         []any          `json:"bodyPatterns"`
+	BasicAuthCredentials map[string]any `json:"basicAuthCredentials"`
+}
+
+type wireMockResponse struct {
+	Status                 int               `json:"status"`
+	Body                   string            `json:"body"`
+	JSONBody               json.RawMessage   `json:"jsonBody"`
+	Base64Body             string            `json:"base64Body"`
+	BodyFileName           string            `json:"bodyFileName"`
+	Headers                map[string]any    `json:"headers"`
+	FixedDelayMilliseconds int               `json:"fixedDelayMilliseconds"`
+	DelayDistribution      map[string]any    `json:"delayDistribution"`
+	Fault                  string            `json:"fault"`
+	Transformers           []string          `json:"transformers"`
+	ProxyBaseURL           string            `json:"proxyBaseUrl"`
+	AdditionalProxyHeaders map[string]string `json:"additionalProxyRequestHeaders"`
+}
+
+type wireMockMapping struct {
+	Id                    string           `json:"id"`
+	Name                  string           `json:"name"`
+	Request               wireMockRequest  `json:"request"`
+	Response              wireMockResponse `json:"response"`
+	Priority              int              `json:"priority"`
+	ScenarioName          string           `json:"scenarioName"`
+	RequiredScenarioState string           `json:"requiredScenarioState"`
+	NewScenarioState      string           `json:"newScenarioState"`
+}
+
+// WireMock converts a WireMock stub file (a single mapping or a {"mappings": [...]} file)
+// to mocked requests.
+func WireMock(data []byte) (*Result, error) {
+	var file struct {
+		Mappings []wireMockMapping `json:"mappings"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if file.Mappings == nil {
+		var mapping wireMockMapping
+		if err := json.Unmarshal(data, &mapping); err != nil {
+			return nil, err
+		}
+		if mapping.Request.Method == "" && mapping.Response.Status == 0 {
+			return nil, errors.New("no wiremock mapping found")
+		}
+		file.Mappings = []wireMockMapping{mapping}
+	}
+
+	result := &Result{}
+	for i, mapping := range file.Mappings {
+		source := "mapping[" + mapping.name(i) + "]"
+		if mock := mapping.toMockedRequest(source, result); mock != nil {
+			result.Mocks = append(result.Mocks, *mock)
+		}
+	}
+
+	return result, nil
+}
+
+func (m wireMockMapping) name(index int) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	if m.Id != "" {
+		return m.Id
+	}
+	return "#" + strconv.Itoa(index)
+}
+
+func (m wireMockMapping) toMockedRequest(source string, result *Result) *internal.MockedRequest {
+	status := m.Response.Status
+	if status == 0 {
+		status = 200
+	}
+	if _, is := pkg.HTTP_CODES[status]; !is {
+		result.unsupported(source, "status {%d} is not supported, mapping ignored", status)
+		return nil
+	}
+
+	matcher := &internal.MockedRequestMatcher{}
+	if method := strings.ToUpper(m.Request.Method); method != "" && method != "ANY" {
+		matcher.Method = method
+	}
+
+	path := ""
+	switch {
+	case m.Request.URLPath != "":
+		path = m.Request.URLPath
+	case m.Request.URL != "":
+		u, err := url.Parse(m.Request.URL)
+		if err != nil {
+			result.unsupported(source, "url {%s} cannot be parsed", m.Request.URL)
+		} else {
+			path = u.Path
+			for key, values := range u.Query() {
+				matcher.Query = setValue(matcher.Query, key, values[0])
+			}
+		}
+	case m.Request.URLPattern != "" || m.Request.URLPathPattern != "":
+		result.unsupported(source, "url pattern {%s} is not supported, the mock is only reachable by its id",
+			m.Request.URLPattern+m.Request.URLPathPattern)
+	}
+
+	for key, value := range m.Request.QueryParameters {
+		if v, ok := equalTo(value); ok {
+			matcher.Query = setValue(matcher.Query, key, v)
+		} else {
+			result.unsupported(source, "request query parameter {%s} matcher is not supported, only {equalTo} is", key)
+		}
+	}
+	headers := map[string]string{}
+	for key, value := range m.Request.Headers {
+		if v, ok := equalTo(value); ok {
+			headers[key] = v
+		} else {
+			result.unsupported(source, "request header {%s} matcher is not supported, only {equalTo} is", key)
+		}
+	}
+	matcher.Headers = requestHeaders(source, headers, result)
+
+	if len(m.Request.Cookies) > 0 {
+		result.unsupported(source, "request cookies matching is not supported")
+	}
+	if len(m.Request.BodyPatterns) > 0 {
+		result.unsupported(source, "request body patterns are not supported")
+	}
+	if len(m.Request.BasicAuthCredentials) > 0 {
+		result.unsupported(source, "request basic auth credentials are not supported")
+	}
+	if m.Priority != 0 {
+		result.unsupported(source, "priority is not supported")
+	}
+	if m.ScenarioName != "" || m.RequiredScenarioState != "" || m.NewScenarioState != "" {
+		result.unsupported(source, "scenarios are not supported")
+	}
+
+	if m.Response.FixedDelayMilliseconds > 0 || len(m.Response.DelayDistribution) > 0 {
+		result.unsupported(source, "response delay is not supported, use the {delay} parameter on the mocked URL")
+	}
+	if m.Response.Fault != "" {
+		result.unsupported(source, "response fault {%s} is not supported", m.Response.Fault)
+	}
+	if len(m.Response.Transformers) > 0 {
+		result.unsupported(source, "response transformers {%s} are not supported", strings.Join(m.Response.Transformers, ","))
+	}
+	if m.Response.ProxyBaseURL != "" {
+		result.unsupported(source, "proxying to {%s} is not supported", m.Response.ProxyBaseURL)
+	}
+
+	var body []byte
+	switch {
+	case m.Response.Body != "":
+		body = []byte(m.Response.Body)
+	case len(m.Response.JSONBody) > 0:
+		body = m.Response.JSONBody
+	case m.Response.Base64Body != "":
+		decoded, err := base64.StdEncoding.DecodeString(m.Response.Base64Body)
+		if err != nil {
+			result.unsupported(source, "response base64 body cannot be decoded")
+		}
+		body = decoded
+	case m.Response.BodyFileName != "":
+		result.unsupported(source, "response body file {%s} is not supported", m.Response.BodyFileName)
+	}
+
+	responseHeaders := map[string]string{}
+	keys := make([]string, 0, len(m.Response.Headers))
+	for key := range m.Response.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch value := m.Response.Headers[key].(type) {
+		case string:
+			responseHeaders[key] = value
+		case []any:
+			values := make([]string, 0, len(value))
+			for _, v := range value {
+				if s, ok := v.(string); ok {
+					values = append(values, s)
+				}
+			}
+			responseHeaders[key] = strings.Join(values, ", ")
+		}
+	}
+
+	if len(m.Response.JSONBody) > 0 && !hasHeader(responseHeaders, "Content-Type") {
+		responseHeaders["Content-Type"] = "application/json"
+	}
+
+	mock := newMockedRequest(status, path, responseHeaders, body)
+	if matcher.Method != "" || matcher.Query != nil || matcher.Headers != nil {
+		mock.Request = matcher
+	}
+	return &mock
+}
+
+// equalTo returns the value of the {"equalTo": "value"} matcher.
+func equalTo(matcher any) (string, bool) {
+	values, ok := matcher.(map[string]any)
+	if !ok || len(values) != 1 {
+		return "", false
+	}
+	value, ok := values["equalTo"].(string)
+	return value, ok
+}
+
+func setValue(values map[string]string, key, value string) map[string]string {
+	if values == nil {
+		values = map[string]string{}
+	}
+	values[key] = value
+	return values
+}
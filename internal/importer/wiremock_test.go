@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestWireMock calls WireMock([]byte),
+// checking for a valid return value.
+func TestWireMock(t *testing.T) {
+	data := `{
+		"mappings": [
+			{
+				"name": "currencies",
+				"request": {"method": "GET", "url": "/currencies?base=EUR"},
+				"response": {
+					"status": 200,
+					"jsonBody": {"EUR": 1},
+					"headers": {"x-domain": "github.com", "Content-Length": "10"}
+				}
+			},
+			{
+				"name": "error",
+				"request": {"method": "ANY", "urlPath": "/error", "headers": {"x-key": {"equalTo": "value"}, "x-regex": {"matches": ".*"}}},
+				"response": {
+					"status": 500,
+					"body": "<error/>",
+					"headers": {"Content-Type": "application/xml; charset=iso-8859-1"},
+					"fixedDelayMilliseconds": 100
+				}
+			},
+			{
+				"name": "image",
+				"request": {"urlPattern": "/images/.*"},
+				"response": {"base64Body": "SGVsbG8gV29ybGQ=", "headers": {"Content-Type": "image/webp"}}
+			},
+			{
+				"name": "bad-status",
+				"request": {"url": "/bad"},
+				"response": {"status": 999}
+			}
+		]
+	}`
+
+	r, err := WireMock([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []internal.MockedRequest{
+		{
+			MockedRequestLight: internal.MockedRequestLight{
+				MockedRequestHeader: internal.MockedRequestHeader{
+					Status:      200,
+					ContentType: "application/json",
+					Charset:     "UTF-8",
+					Headers:     map[string]string{"x-domain": "github.com"},
+					Path:        "/currencies",
+					Request:     &internal.MockedRequestMatcher{Method: "GET", Query: map[string]string{"base": "EUR"}},
+				},
+			},
+			Body64: []byte(`{"EUR": 1}`),
+		},
+		{
+			MockedRequestLight: internal.MockedRequestLight{
+				MockedRequestHeader: internal.MockedRequestHeader{
+					Status:      500,
+					ContentType: "application/xml",
+					Charset:     "ISO-8859-1",
+					Headers:     map[string]string{},
+					Path:        "/error",
+					Request:     &internal.MockedRequestMatcher{Headers: map[string]string{"x-key": "value"}},
+				},
+			},
+			Body64: []byte("<error/>"),
+		},
+		{
+			MockedRequestLight: internal.MockedRequestLight{
+				MockedRequestHeader: internal.MockedRequestHeader{
+					Status:      200,
+					ContentType: "text/plain",
+					Charset:     "UTF-8",
+					Headers:     map[string]string{"Content-Type": "image/webp"},
+				},
+			},
+			Body64: []byte("Hello World"),
+		},
+	}
+
+	if len(r.Mocks) != len(expected) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r.Mocks, expected)
+	}
+	for i, mock := range r.Mocks {
+		if !mock.Equals(expected[i]) {
+			t.Fatalf(`result: {%v} but expected {%v}`, mock, expected[i])
+		}
+	}
+
+	for _, unsupported := range []string{
+		"mapping[error]: request header {x-regex} matcher is not supported, only {equalTo} is",
+		"mapping[error]: response delay is not supported, use the {delay} parameter on the mocked URL",
+		"mapping[image]: url pattern {/images/.*} is not supported, the mock is only reachable by its id",
+		"mapping[bad-status]: status {999} is not supported, mapping ignored",
+	} {
+		if !slicesutil.Exist(r.Unsupported, unsupported) {
+			t.Fatalf(`result: {%v} but expected {%v}`, r.Unsupported, unsupported)
+		}
+	}
+}
+
+// TestWireMockWithSingleMapping calls WireMock([]byte),
+// checking for a valid return value.
+func TestWireMockWithSingleMapping(t *testing.T) {
+	data := `{"request": {"method": "GET", "urlPath": "/hello"}, "response": {"status": 200, "body": "Hello World"}}`
+
+	r, err := WireMock([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(r.Mocks) != 1 || r.Mocks[0].Path != "/hello" || string(r.Mocks[0].Body64) != "Hello World" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r.Mocks, "/hello")
+	}
+}
+
+// TestWireMockWithBadData calls WireMock([]byte),
+// checking for a valid return value.
+func TestWireMockWithBadData(t *testing.T) {
+	if r, err := WireMock([]byte("bad data")); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, r)
+	}
+
+	if _, err := WireMock([]byte("{}")); err == nil || !strings.Contains(err.Error(), "no wiremock mapping found") {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "no wiremock mapping found")
+	}
+}
+
+// TestImport calls Import(string, []byte),
+// checking for a valid return value.
+func TestImport(t *testing.T) {
+	if _, err := Import("wrong-format", []byte("{}")); err == nil || err.Error() != "format {wrong-format} does not exist" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "format {wrong-format} does not exist")
+	}
+
+	r, err := Import("wiremock", []byte(`{"mappings": [{"request": {"url": "/"}, "response": {"status": 204}}]}`))
+	if err != nil || len(r.Mocks) != 1 || r.Mocks[0].Status != 204 {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, 204)
+	}
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/joakim-ribier/go-utils/pkg/logsutil"
+)
+
+// index represents the {MockedRequestLight} of the stored mocked requests,
+// loaded once from the store and updated on each write so that only the bodies are read from the store.
+type index struct {
+	mu     sync.RWMutex
+	values map[string]MockedRequestLight
+}
+
+func newIndex() *index {
+	return &index{}
+}
+
+// load reads the stored mocked requests if the index is not loaded yet.
+func (i *index) load(store Store, logger logsutil.Logger) error {
+	i.mu.RLock()
+	loaded := i.values != nil
+	i.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.values != nil {
+		return nil
+	}
+
+	ids, err := store.Ids()
+	if err != nil {
+		logger.Error(err, "error to list identifiers", "store", store)
+		return err
+	}
+
+	values := make(map[string]MockedRequestLight, len(ids))
+	for _, mockId := range ids {
+		if mock, err := get[MockedRequestLight](store, mockId, logger); err == nil {
+			values[mockId] = *mock
+		}
+	}
+	i.values = values
+	return nil
+}
+
+// list returns the indexed mocked requests.
+func (i *index) list() []MockedRequestLight {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	values := make([]MockedRequestLight, 0, len(i.values))
+	for _, value := range i.values {
+		values = append(values, value)
+	}
+	return values
+}
+
+// put adds or replaces the mocked request {mock} if the index is loaded.
+func (i *index) put(mock MockedRequestLight) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.values != nil {
+		i.values[mock.Id] = mock
+	}
+}
+
+// remove deletes the mocked request {mockId} from the index.
+func (i *index) remove(mockId string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.values, mockId)
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"testing"
+)
+
+// countingStore represents a {Store} counting the reads
+type countingStore struct {
+	MemoryStore
+	reads *int
+}
+
+func (s countingStore) Read(mockId string) ([]byte, error) {
+	*s.reads = *s.reads + 1
+	return s.MemoryStore.Read(mockId)
+}
+
+// TestListFromIndex calls Mock.List, Mock.Add and Mock.Clean,
+// checking that the mocked requests are listed without reading the store.
+func TestListFromIndex(t *testing.T) {
+	reads := 0
+	store := countingStore{MemoryStore: NewMemoryStore(), reads: &reads}
+	store.Write("existing", []byte(`{"id":"existing","createdAt":"2000-01-01 00:00:00","status":200}`))
+
+	mocker := NewMockWithStore(store, nil, *logger)
+	if values, err := mocker.List(); err != nil || len(values) != 1 || reads != 1 {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, "existing")
+	}
+
+	added, err := mocker.Add(MockedRequest{
+		MockedRequestLight: MockedRequestLight{
+			MockedRequestHeader: MockedRequestHeader{Status: 200, ContentType: "text/plain", Charset: "UTF-8", Path: "/hello"},
+		},
+		Body64: []byte("Hello World"),
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	values, err := mocker.List()
+	if err != nil || len(values) != 2 || values[0].Id != added.Id || values[0].Path != "/hello" || reads != 1 {
+		t.Fatalf(`result: {%v} (reads: %d) but expected {%v}`, values, reads, added.Id)
+	}
+
+	if cleaned, err := mocker.Clean(1); err != nil || len(cleaned) != 1 {
+		t.Fatalf(`result: {%v} but expected {%v}`, cleaned, 1)
+	}
+	if values, err := mocker.List(); err != nil || len(values) != 1 || values[0].Id != added.Id || reads != 1 {
+		t.Fatalf(`result: {%v} (reads: %d) but expected {%v}`, values, reads, added.Id)
+	}
+
+	// the body is loaded from the store
+	if mock, err := mocker.Get(added.Id); err != nil || string(mock.Body64) != "Hello World" || reads != 2 {
+		t.Fatalf(`result: {%v} (reads: %d) but expected {%v}`, mock, reads, "Hello World")
+	}
+}
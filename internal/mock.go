@@ -2,16 +2,18 @@ package internal
 
 import (
 	"bytes"
+	"crypto/x509"
 	"fmt"
-	"io/fs"
+	"maps"
+	"net/http"
 	"net/url"
-	"os"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/joakim-ribier/go-utils/pkg/iosutil"
 	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
 	"github.com/joakim-ribier/go-utils/pkg/logsutil"
 	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
@@ -19,24 +21,165 @@ import (
 	"github.com/joakim-ribier/mockapic/pkg"
 )
 
+// MockedRequestMatcher represents the conditions that the incoming request must match
+type MockedRequestMatcher struct {
+	Method  string            `json:"method,omitempty"`
+	Query   map[string]string `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// ClientSubject is the common name or the distinguished name of the client certificate (mTLS)
+	ClientSubject string `json:"clientSubject,omitempty"`
+	// ClientSAN is one of the DNS names, email addresses, IP addresses or URIs of the client certificate (mTLS)
+	ClientSAN string `json:"clientSan,omitempty"`
+	// Protocol is the protocol version of the request: {HTTP/1.1} (or {1.1}), {HTTP/2} (or {2})
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// Match returns true if the incoming request {r} matches all the conditions.
+func (m *MockedRequestMatcher) Match(r *http.Request) bool {
+	if m == nil {
+		return true
+	}
+	if m.Method != "" && !strings.EqualFold(m.Method, r.Method) {
+		return false
+	}
+	query := r.URL.Query()
+	for key, value := range m.Query {
+		if !slicesutil.Exist(query[key], value) {
+			return false
+		}
+	}
+	for key, value := range m.Headers {
+		if r.Header.Get(key) != value {
+			return false
+		}
+	}
+	if m.Protocol != "" && !matchProtocol(m.Protocol, r) {
+		return false
+	}
+	if m.ClientSubject != "" || m.ClientSAN != "" {
+		cert := ClientCertificate(r)
+		if cert == nil {
+			return false
+		}
+		if m.ClientSubject != "" && m.ClientSubject != cert.Subject.CommonName && m.ClientSubject != cert.Subject.String() {
+			return false
+		}
+		if m.ClientSAN != "" && !slices.Contains(CertificateSANs(cert), m.ClientSAN) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns true if the matcher has the same conditions as the {other} one (nil is a matcher without condition).
+func (m *MockedRequestMatcher) Equal(other *MockedRequestMatcher) bool {
+	if m == nil {
+		m = &MockedRequestMatcher{}
+	}
+	if other == nil {
+		other = &MockedRequestMatcher{}
+	}
+	return strings.EqualFold(m.Method, other.Method) &&
+		maps.Equal(m.Query, other.Query) &&
+		maps.Equal(m.Headers, other.Headers) &&
+		m.ClientSubject == other.ClientSubject &&
+		m.ClientSAN == other.ClientSAN &&
+		m.Protocol == other.Protocol
+}
+
+// matchProtocol returns true if the {protocol} version (e.g. {HTTP/2}, {2}, {HTTP/1.1} or {1.1}) is the one of the request {r}.
+func matchProtocol(protocol string, r *http.Request) bool {
+	version := strings.TrimPrefix(strings.ToUpper(protocol), "HTTP/")
+	return version == strconv.Itoa(r.ProtoMajor) || version == fmt.Sprintf("%d.%d", r.ProtoMajor, r.ProtoMinor)
+}
+
+// ClientCertificate returns the certificate presented by the client of the request {r} (mTLS) or nil.
+func ClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return r.TLS.PeerCertificates[0]
+}
+
+// CertificateSANs returns the DNS names, email addresses, IP addresses and URIs of the {cert}.
+func CertificateSANs(cert *x509.Certificate) []string {
+	sans := append([]string{}, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	return sans
+}
+
+// MockedRequestRateLimit represents the token bucket limiting the calls to a mocked request:
+// {Limit} calls per {Period}, then the {MockId} mocked request (or a 429 status) is returned
+type MockedRequestRateLimit struct {
+	Limit  int    `json:"limit"`
+	Period string `json:"period"`
+	MockId string `json:"mockId,omitempty"`
+}
+
+// ParseRateLimit parses a rate limit {value} formatted as {limit}/{period} (e.g. 100/1m).
+func ParseRateLimit(value string) (*MockedRequestRateLimit, error) {
+	limit, period, _ := strings.Cut(value, "/")
+	rateLimit := &MockedRequestRateLimit{Limit: stringsutil.Int(limit, -1), Period: period}
+	if _, err := rateLimit.Interval(); err != nil {
+		return nil, err
+	}
+	return rateLimit, nil
+}
+
+// Interval returns the period of the rate limit.
+func (m MockedRequestRateLimit) Interval() (time.Duration, error) {
+	period, err := time.ParseDuration(m.Period)
+	if err != nil || period <= 0 || m.Limit < 1 {
+		return 0, fmt.Errorf("rate limit {%d/%s} is not valid", m.Limit, m.Period)
+	}
+	return period, nil
+}
+
 type MockedRequestHeader struct {
-	Status      int               `json:"status,omitempty"`
-	ContentType string            `json:"contentType,omitempty"`
-	Charset     string            `json:"charset,omitempty"`
-	Headers     map[string]string `json:"headers,omitempty"`
-	Path        string            `json:"path,omitempty"`
+	Status      int                     `json:"status,omitempty"`
+	ContentType string                  `json:"contentType,omitempty"`
+	Charset     string                  `json:"charset,omitempty"`
+	Headers     map[string]string       `json:"headers,omitempty"`
+	Trailers    map[string]string       `json:"trailers,omitempty"`
+	Push        []string                `json:"push,omitempty"`
+	Path        string                  `json:"path,omitempty"`
+	BodyFile    string                  `json:"bodyFile,omitempty"`
+	Request     *MockedRequestMatcher   `json:"request,omitempty"`
+	RateLimit   *MockedRequestRateLimit `json:"rateLimit,omitempty"`
+	Auth        *MockedRequestAuth      `json:"auth,omitempty"`
 }
 
 type MockedRequestLight struct {
 	Id        string `json:"id,omitempty"`
 	CreatedAt string `json:"createdAt,omitempty"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	Pinned    bool   `json:"pinned,omitempty"`
+	Source    string `json:"source,omitempty"`
 	MockedRequestHeader
 }
 
+// Expired returns true if the mocked request has an expiration time before {now}.
+func (m MockedRequestLight) Expired(now time.Time) bool {
+	if m.ExpiresAt == "" {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, m.ExpiresAt)
+	return err == nil && !now.Before(expiresAt)
+}
+
 type MockedRequest struct {
 	MockedRequestLight
-	Body   string `json:"body,omitempty"`
-	Body64 []byte `json:"body64,omitempty"`
+	Body      string `json:"body,omitempty"`
+	Body64    []byte `json:"body64,omitempty"`
+	TokenHash string `json:"tokenHash,omitempty"`
+	// Token is the owner token generated on creation, only its hash is stored
+	Token string `json:"-"`
 }
 
 func NewMockedRequestFromHttpCode(httpCode int, body string) MockedRequest {
@@ -58,16 +201,15 @@ type PredefinedMockedRequest struct {
 	MockedRequest
 }
 
-func (m PredefinedMockedRequest) toMockedRequest() *MockedRequest {
+// ToMockedRequest converts the predefined mocked request, the {body} field takes precedence over the {body64} one.
+func (m PredefinedMockedRequest) ToMockedRequest() *MockedRequest {
 	body := m.Body64
 	if len(m.Body) > 0 {
 		body = []byte(m.Body)
 	}
 	return &MockedRequest{
-		MockedRequestLight: MockedRequestLight{
-			MockedRequestHeader: m.MockedRequestHeader,
-			Id:                  m.Id},
-		Body64: body,
+		MockedRequestLight: m.MockedRequestLight,
+		Body64:             body,
 	}
 }
 
@@ -78,79 +220,113 @@ func (m MockedRequest) Equals(arg MockedRequest) bool {
 		m.Charset == arg.Charset &&
 		m.Body == arg.Body &&
 		m.Path == arg.Path &&
+		m.BodyFile == arg.BodyFile &&
 		bytes.Equal(m.Body64, arg.Body64) &&
-		reflect.DeepEqual(m.Headers, arg.Headers)
+		reflect.DeepEqual(m.Headers, arg.Headers) &&
+		reflect.DeepEqual(m.Trailers, arg.Trailers) &&
+		reflect.DeepEqual(m.Push, arg.Push) &&
+		reflect.DeepEqual(m.Request, arg.Request) &&
+		reflect.DeepEqual(m.RateLimit, arg.RateLimit) &&
+		reflect.DeepEqual(m.Auth, arg.Auth)
 }
 
 type Mocker interface {
 	Get(mockId string) (*MockedRequest, error)
+	Touch(mockId string)
 	List() ([]MockedRequestLight, error)
 	New(params map[string][]string, body []byte) (*MockedRequest, error)
-	Clean(maxLimit int) (int, error)
+	Add(mock MockedRequest) (*MockedRequest, error)
+	Update(mockId string, params map[string][]string, body []byte) (*MockedRequest, error)
+	Delete(mockId string) error
+	Clean(maxLimit int) ([]MockedRequestLight, error)
+	Purge(now time.Time) ([]MockedRequestLight, error)
 }
 
 type Mock struct {
-	workingDirectory         string
-	logger                   logsutil.Logger
-	predefinedMockedRequests []PredefinedMockedRequest
+	store      Store
+	logger     logsutil.Logger
+	predefined *predefined
+	eviction   string
+	usage      *usage
+	index      *index
 }
 
 func NewMock(workingDirectory string, predefinedMockedRequests []PredefinedMockedRequest, logger logsutil.Logger) Mock {
+	return NewMockWithStore(NewFileStore(workingDirectory), predefinedMockedRequests, logger)
+}
+
+// NewMockWithStore creates a {Mock} which persists the mocked requests in the {store}.
+func NewMockWithStore(store Store, predefinedMockedRequests []PredefinedMockedRequest, logger logsutil.Logger) Mock {
 	return Mock{
-		workingDirectory:         workingDirectory,
-		logger:                   logger.Namespace("mock"),
-		predefinedMockedRequests: predefinedMockedRequests}
+		store:      store,
+		logger:     logger.Namespace("mock"),
+		predefined: &predefined{values: predefinedMockedRequests},
+		eviction:   EVICTION_CREATED,
+		usage:      newUsage(),
+		index:      newIndex()}
+}
+
+// WithEviction sets the {policy} used by {Clean} to choose the mocked requests to remove.
+func (m Mock) WithEviction(policy string) Mock {
+	m.eviction = policy
+	return m
 }
 
 // Get finds the mocked request by {mockId} value on the storage or in the predefined requests.
 func (m Mock) Get(mockId string) (*MockedRequest, error) {
-	mock, err := get[MockedRequest](m.workingDirectory, mockId, m.logger)
+	mock, err := get[MockedRequest](m.store, mockId, m.logger)
 	if mock != nil {
 		return mock, nil
 	}
 
-	if mock := slicesutil.FindT[PredefinedMockedRequest](
-		m.predefinedMockedRequests, func(mr PredefinedMockedRequest) bool { return mr.Id == mockId }); mock != nil {
-		return mock.toMockedRequest(), nil
+	if mock := m.findPredefined(mockId); mock != nil {
+		return mock.ToMockedRequest(), nil
 	}
 
 	return nil, err
 }
 
-func get[T any](workingDirectory, mockId string, logger logsutil.Logger) (*T, error) {
-	bytes, err := iosutil.Load(workingDirectory + "/" + mockId + ".json")
+// Touch records an access to the mocked request {mockId} served by a mocked endpoint for the eviction policy.
+func (m Mock) Touch(mockId string) {
+	m.usage.hit(mockId, time.Now())
+}
+
+// SetPredefined replaces the predefined mocked requests and returns the previous ones.
+func (m Mock) SetPredefined(values []PredefinedMockedRequest) []PredefinedMockedRequest {
+	return m.predefined.set(values)
+}
+
+func (m Mock) findPredefined(mockId string) *PredefinedMockedRequest {
+	return slicesutil.FindT[PredefinedMockedRequest](
+		m.predefined.get(), func(mr PredefinedMockedRequest) bool { return mr.Id == mockId })
+}
+
+func get[T any](store Store, mockId string, logger logsutil.Logger) (*T, error) {
+	bytes, err := store.Read(mockId)
 	if err != nil {
-		logger.Error(err, "error to load data", "mockId", mockId, "workingDirectory", workingDirectory)
+		logger.Error(err, "error to load data", "mockId", mockId, "store", store)
 		return nil, err
 	}
 
 	mock, err := jsonsutil.Unmarshal[T](bytes)
 	if err != nil {
-		logger.Error(err, "error to unmarshal data", "mockId", mockId, "workingDirectory", workingDirectory)
+		logger.Error(err, "error to unmarshal data", "mockId", mockId, "store", store)
 		return nil, err
 	}
 	return &mock, nil
 }
 
-// List gets all mocked requests on the storage and the predefined requests.
+// List gets all mocked requests from the index of the storage and the predefined requests.
 func (m Mock) List() ([]MockedRequestLight, error) {
-	fileEntries, err := os.ReadDir(m.workingDirectory + "/")
-	if err != nil {
-		m.logger.Error(err, "error to read directory", "workingDirectory", m.workingDirectory)
+	if err := m.index.load(m.store, m.logger); err != nil {
 		return nil, err
 	}
 
-	mockedRequestsLight := slicesutil.TransformT[fs.DirEntry, MockedRequestLight](fileEntries, func(e fs.DirEntry) (*MockedRequestLight, error) {
-		var mockId string = ""
-		if len(e.Name()) > 5 {
-			mockId = e.Name()[:len(e.Name())-5]
-		}
-		return get[MockedRequestLight](m.workingDirectory, mockId, m.logger)
-	})
+	mockedRequestsLight := m.index.list()
 
-	if len(m.predefinedMockedRequests) > 0 {
+	if predefinedMockedRequests := m.predefined.get(); len(predefinedMockedRequests) > 0 {
 		mockedRequestsLight = append(mockedRequestsLight, slicesutil.TransformT[PredefinedMockedRequest, MockedRequestLight](
-			m.predefinedMockedRequests, func(lmr PredefinedMockedRequest) (*MockedRequestLight, error) {
+			predefinedMockedRequests, func(lmr PredefinedMockedRequest) (*MockedRequestLight, error) {
 				return &lmr.MockedRequestLight, nil
 			})...)
 	}
@@ -161,8 +337,58 @@ func (m Mock) List() ([]MockedRequestLight, error) {
 		}), nil
 }
 
+// Ping checks that the storage of the mocked requests is available.
+func (m Mock) Ping() error {
+	_, err := m.store.Ids()
+	return err
+}
+
 // New creates a new mocked request and returns the new identifier.
 func (m Mock) New(reqParams map[string][]string, reqBody []byte) (*MockedRequest, error) {
+	mock, err := m.parse(reqParams, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	return m.Add(*mock)
+}
+
+// Update replaces the stored mocked request {mockId} by the one of the {reqParams} and {reqBody},
+// its identifier, creation date and owner token are kept.
+func (m Mock) Update(mockId string, reqParams map[string][]string, reqBody []byte) (*MockedRequest, error) {
+	if m.findPredefined(mockId) != nil {
+		return nil, fmt.Errorf("mock {%s} is predefined and cannot be updated", mockId)
+	}
+
+	existing, err := get[MockedRequest](m.store, mockId, m.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	mock, err := m.parse(reqParams, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	mock.Id, mock.CreatedAt, mock.TokenHash = existing.Id, existing.CreatedAt, existing.TokenHash
+	return m.Add(*mock)
+}
+
+// Delete removes the stored mocked request {mockId}.
+func (m Mock) Delete(mockId string) error {
+	if m.findPredefined(mockId) != nil {
+		return fmt.Errorf("mock {%s} is predefined and cannot be deleted", mockId)
+	}
+
+	if err := m.store.Delete(mockId); err != nil {
+		m.logger.Error(err, "error to delete data", "mockId", mockId, "store", m.store)
+		return err
+	}
+	m.index.remove(mockId)
+	m.usage.remove(mockId)
+	return nil
+}
+
+// parse creates the mocked request of the {reqParams} and {reqBody} without storing it.
+func (m Mock) parse(reqParams map[string][]string, reqBody []byte) (*MockedRequest, error) {
 	mock := &MockedRequest{
 		MockedRequestLight: MockedRequestLight{
 			Id:                  uuid.NewString(),
@@ -195,23 +421,122 @@ func (m Mock) New(reqParams map[string][]string, reqBody []byte) (*MockedRequest
 			mock.Status = stringsutil.Int(getReqParam(name, values), -1)
 		case "path":
 			mock.Path = getReqParam(name, values)
+		case "bodyFile":
+			mock.BodyFile = getReqParam(name, values)
+		case "ttl":
+			value := getReqParam(name, values)
+			ttl, err := time.ParseDuration(value)
+			if err != nil || ttl <= 0 {
+				return nil, fmt.Errorf("ttl {%s} is not valid", value)
+			}
+			mock.ExpiresAt = time.Now().Add(ttl).Format(time.RFC3339)
+		case "expiresAt":
+			mock.ExpiresAt = getReqParam(name, values)
+		case "pinned":
+			mock.Pinned = stringsutil.Bool(getReqParam(name, values))
+		case "clientSubject", "clientSan", "protocol":
+			// read with the {request} matcher
+		case "push":
+			mock.Push = slicesutil.FilterByNonEmpty(strings.Split(getReqParam(name, values), ","))
+		case "rateLimit":
+			rateLimit, err := ParseRateLimit(getReqParam(name, values))
+			if err != nil {
+				return nil, err
+			}
+			mock.RateLimit = rateLimit
+		case "rateLimitMockId":
+			// read with the {rateLimit} parameter
+		case "auth":
+			mock.Auth = &MockedRequestAuth{Type: getReqParam(name, values)}
+		case "authName", "authIn", "authValues", "authScope", "authSecret", "authAudience", "authClaims", "authMockId":
+			// read with the {auth} parameter
 		default:
-			if len(values) > 0 {
+			if trailer, ok := strings.CutPrefix(name, "trailer:"); ok && len(values) > 0 {
+				if mock.Trailers == nil {
+					mock.Trailers = map[string]string{}
+				}
+				mock.Trailers[trailer] = getReqParam(name, values)
+			} else if len(values) > 0 {
 				mock.Headers[name] = getReqParam(name, values)
 			}
 		}
 	}
 
+	if mock.RateLimit != nil {
+		mock.RateLimit.MockId = getReqParam("rateLimitMockId", reqParams["rateLimitMockId"])
+	}
+
+	matcher := MockedRequestMatcher{
+		ClientSubject: getReqParam("clientSubject", reqParams["clientSubject"]),
+		ClientSAN:     getReqParam("clientSan", reqParams["clientSan"]),
+		Protocol:      getReqParam("protocol", reqParams["protocol"]),
+	}
+	if matcher.ClientSubject != "" || matcher.ClientSAN != "" || matcher.Protocol != "" {
+		mock.Request = &matcher
+	}
+
+	if mock.Auth != nil {
+		mock.Auth.Name = getReqParam("authName", reqParams["authName"])
+		mock.Auth.In = getReqParam("authIn", reqParams["authIn"])
+		mock.Auth.Values = slicesutil.FilterByNonEmpty(strings.Split(getReqParam("authValues", reqParams["authValues"]), ","))
+		mock.Auth.Scope = getReqParam("authScope", reqParams["authScope"])
+		mock.Auth.Secret = getReqParam("authSecret", reqParams["authSecret"])
+		mock.Auth.Audience = getReqParam("authAudience", reqParams["authAudience"])
+		mock.Auth.Claims = slicesutil.FilterByNonEmpty(strings.Split(getReqParam("authClaims", reqParams["authClaims"]), ","))
+		mock.Auth.MockId = getReqParam("authMockId", reqParams["authMockId"])
+	}
+
+	return mock, nil
+}
+
+// Add validates and stores the provided mocked request and returns it with its identifier,
+// an owner token is generated if the mocked request does not have one.
+func (m Mock) Add(mock MockedRequest) (*MockedRequest, error) {
+	if mock.Id == "" {
+		mock.Id = uuid.NewString()
+	}
+	if mock.CreatedAt == "" {
+		mock.CreatedAt = time.Now().Format("2006-01-02 15:04:05")
+	}
+	if mock.Headers == nil {
+		mock.Headers = map[string]string{}
+	}
+	if mock.TokenHash == "" {
+		mock.Token = NewToken()
+		mock.TokenHash = HashToken(mock.Token)
+	}
+
 	if _, is := pkg.HTTP_CODES[mock.Status]; !is {
 		return nil, fmt.Errorf("status {%d} does not exist", mock.Status)
 	}
 
-	if !slicesutil.Exist(pkg.CONTENT_TYPES, mock.ContentType) {
-		return nil, fmt.Errorf("content type {%s} does not exist", mock.ContentType)
+	// the content type of a body file is inferred if it is not specified
+	if mock.BodyFile == "" || mock.ContentType != "" {
+		if !slicesutil.Exist(pkg.CONTENT_TYPES, mock.ContentType) {
+			return nil, fmt.Errorf("content type {%s} does not exist", mock.ContentType)
+		}
+
+		if !slicesutil.Exist(pkg.CHARSET, mock.Charset) {
+			return nil, fmt.Errorf("charset {%s} does not exist", mock.Charset)
+		}
+	}
+
+	if mock.RateLimit != nil {
+		if _, err := mock.RateLimit.Interval(); err != nil {
+			return nil, err
+		}
+	}
+
+	if mock.Auth != nil {
+		if err := mock.Auth.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
-	if !slicesutil.Exist(pkg.CHARSET, mock.Charset) {
-		return nil, fmt.Errorf("charset {%s} does not exist", mock.Charset)
+	if mock.ExpiresAt != "" {
+		if _, err := time.Parse(time.RFC3339, mock.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("expiresAt {%s} is not valid", mock.ExpiresAt)
+		}
 	}
 
 	bytes, err := jsonsutil.Marshal(mock)
@@ -220,37 +545,68 @@ func (m Mock) New(reqParams map[string][]string, reqBody []byte) (*MockedRequest
 		return nil, err
 	}
 
-	err = iosutil.Write(bytes, m.workingDirectory+"/"+mock.Id+".json")
+	err = m.store.Write(mock.Id, bytes)
 	if err != nil {
-		m.logger.Error(err, "error to write data", "mock", mock, "workingDirectory", m.workingDirectory)
+		m.logger.Error(err, "error to write data", "mock", mock, "store", m.store)
 		return nil, err
 	}
+	m.index.put(mock.MockedRequestLight)
 
-	return mock, nil
+	return &mock, nil
 }
 
-// Clean removes the x (nb mocked request - max limit) last requests.
-func (m Mock) Clean(maxLimit int) (int, error) {
-	nb := 0
+// Clean removes the x (nb mocked request - max limit) requests chosen by the eviction policy and returns them,
+// the pinned and the predefined requests are never removed.
+func (m Mock) Clean(maxLimit int) ([]MockedRequestLight, error) {
+	var cleaned []MockedRequestLight
 	if maxLimit < 1 {
-		return nb, nil
+		return cleaned, nil
 	}
 
 	mockedRequests, err := m.List()
 	if err != nil {
-		m.logger.Error(err, "error to list requests", "workingDirectory", m.workingDirectory)
-		return nb, err
+		m.logger.Error(err, "error to list requests", "store", m.store)
+		return cleaned, err
 	}
 
 	nbToDelete := len(mockedRequests) - maxLimit
 	if nbToDelete < 1 {
-		return nb, nil
+		return cleaned, nil
+	}
+
+	evictable := m.usage.sort(slicesutil.FilterT(mockedRequests, func(mrl MockedRequestLight) bool {
+		return !mrl.Pinned && m.findPredefined(mrl.Id) == nil
+	}), m.eviction)
+	nbToDelete = min(nbToDelete, len(evictable))
+
+	for _, mockedRequest := range evictable[len(evictable)-nbToDelete:] {
+		if err := m.store.Delete(mockedRequest.Id); err == nil {
+			m.index.remove(mockedRequest.Id)
+			m.usage.remove(mockedRequest.Id)
+			cleaned = append(cleaned, mockedRequest)
+		}
 	}
+	return cleaned, nil
+}
 
-	for _, mockedRequest := range mockedRequests[len(mockedRequests)-nbToDelete:] {
-		if err := os.Remove(m.workingDirectory + "/" + mockedRequest.Id + ".json"); err == nil {
-			nb = nb + 1
+// Purge removes the stored mocked requests expired at {now} and returns them.
+func (m Mock) Purge(now time.Time) ([]MockedRequestLight, error) {
+	if err := m.index.load(m.store, m.logger); err != nil {
+		return nil, err
+	}
+
+	var purged []MockedRequestLight
+	for _, mock := range m.index.list() {
+		if !mock.Expired(now) {
+			continue
+		}
+		if err := m.store.Delete(mock.Id); err != nil {
+			m.logger.Error(err, "error to delete data", "mockId", mock.Id, "store", m.store)
+			continue
 		}
+		m.index.remove(mock.Id)
+		m.usage.remove(mock.Id)
+		purged = append(purged, mock)
 	}
-	return nb, nil
+	return purged, nil
 }
@@ -1,7 +1,13 @@
 package internal
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -172,19 +178,19 @@ func TestClean(t *testing.T) {
 	nbClean, _ := NewMock(workingDirectory, nil, *logger).Clean(1)
 	nbAfter, _ := NewMock(workingDirectory, nil, *logger).List()
 
-	if !(len(nbBefore) > 1 && nbClean > 0 && len(nbAfter) == 1) {
+	if !(len(nbBefore) > 1 && len(nbClean) > 0 && len(nbAfter) == 1) {
 		t.Fatalf(`result: {%v} but expected {%v}`, nbAfter, []string{})
 	}
 
 	// test if the max limit is < 0
 	r, err := NewMock(workingDirectory, nil, *logger).Clean(-1)
-	if r != 0 || err != nil {
+	if len(r) != 0 || err != nil {
 		t.Fatalf(`result: {%v} but expected {%v}`, r, 0)
 	}
 
 	// test if the max limit is > to the total nb mocked request
 	r, err = NewMock(workingDirectory, nil, *logger).Clean(100)
-	if r != 0 || err != nil {
+	if len(r) != 0 || err != nil {
 		t.Fatalf(`result: {%v} but expected {%v}`, r, 0)
 	}
 
@@ -263,6 +269,187 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// TestAdd calls Mocker.Add,
+// checking for a valid return value.
+func TestAdd(t *testing.T) {
+	mockedRequest := MockedRequest{
+		MockedRequestLight: MockedRequestLight{
+			MockedRequestHeader: MockedRequestHeader{
+				Status:      201,
+				ContentType: "application/json",
+				Charset:     "UTF-8",
+				Path:        "/my-added-path",
+			},
+		},
+		Body64: []byte(`{"id":1}`),
+	}
+
+	added, err := NewMock(workingDirectory, nil, *logger).Add(mockedRequest)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove(workingDirectory + "/" + added.Id + ".json")
+
+	mock, err := NewMock(workingDirectory, nil, *logger).Get(added.Id)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if added.Id == "" || added.CreatedAt == "" || !mock.Equals(mockedRequest) {
+		t.Fatalf(`result: {%v} but expected {%v}`, mock, mockedRequest)
+	}
+
+	// test if the status does not exist
+	mockedRequest.Status = 999
+	if _, err := NewMock(workingDirectory, nil, *logger).Add(mockedRequest); err == nil || err.Error() != "status {999} does not exist" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "status {999} does not exist")
+	}
+
+	// the identifier must be a file name of the working directory
+	mockedRequest.Status, mockedRequest.Id = 201, "../escaped"
+	if _, err := NewMock(workingDirectory, nil, *logger).Add(mockedRequest); err == nil || err.Error() != "mock id {../escaped} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "mock id {../escaped} is not valid")
+	}
+	if _, err := os.Stat(workingDirectory + "/../escaped.json"); err == nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, "../escaped.json", "no file")
+	}
+	mockedRequest.Id = ""
+
+	// the content type of a body file can be inferred
+	mockedRequest.Status, mockedRequest.ContentType, mockedRequest.Charset, mockedRequest.BodyFile = 200, "", "", "files/user.json"
+	added, err = NewMockWithStore(NewMemoryStore(), nil, *logger).Add(mockedRequest)
+	if err != nil || added.BodyFile != "files/user.json" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "files/user.json")
+	}
+}
+
+// TestUpdateAndDelete calls Mock.Update and Mock.Delete,
+// checking for a valid return value.
+func TestUpdateAndDelete(t *testing.T) {
+	mock := NewMockWithStore(NewMemoryStore(), []PredefinedMockedRequest{{MockedRequest: MockedRequest{MockedRequestLight: MockedRequestLight{Id: "predefined"}}}}, *logger)
+
+	created, err := mock.New(map[string][]string{"status": {"200"}, "contentType": {"text/plain"}, "charset": {"UTF-8"}, "path": {"/one"}}, []byte("one"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if created.Token == "" || created.TokenHash != HashToken(created.Token) || !created.Owned(created.Token) || created.Owned("bad-token") {
+		t.Fatalf(`result: {%v} but expected {%v}`, created.TokenHash, HashToken(created.Token))
+	}
+
+	updated, err := mock.Update(created.Id, map[string][]string{"status": {"201"}, "contentType": {"text/plain"}, "charset": {"UTF-8"}, "path": {"/two"}}, []byte("two"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	stored, _ := mock.Get(created.Id)
+	if updated.Token != "" || stored.Status != 201 || stored.Path != "/two" || string(stored.Body64) != "two" ||
+		stored.CreatedAt != created.CreatedAt || !stored.Owned(created.Token) {
+		t.Fatalf(`result: {%v} but expected {%v}`, stored, "201 /two")
+	}
+
+	if _, err := mock.Update(created.Id, map[string][]string{"status": {"999"}}, nil); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+	if _, err := mock.Update("predefined", nil, nil); err == nil || err.Error() != "mock {predefined} is predefined and cannot be updated" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "mock {predefined} is predefined and cannot be updated")
+	}
+
+	if err := mock.Delete(created.Id); err != nil {
+		t.Fatal(err.Error())
+	}
+	if values, _ := mock.List(); len(values) != 1 || values[0].Id != "predefined" {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, "predefined")
+	}
+	if err := mock.Delete(created.Id); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+	if err := mock.Delete("predefined"); err == nil || err.Error() != "mock {predefined} is predefined and cannot be deleted" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "mock {predefined} is predefined and cannot be deleted")
+	}
+}
+
+// TestBodyFilePath calls BodyFilePath(string, string),
+// checking for a valid return value.
+func TestBodyFilePath(t *testing.T) {
+	directory := t.TempDir()
+	os.MkdirAll(filepath.Join(directory, "files"), os.ModePerm)
+	os.WriteFile(filepath.Join(directory, "files", "user.json"), []byte(`{"id":1}`), 0644)
+
+	if path, err := BodyFilePath(directory, "files/user.json"); err != nil || path != filepath.Join(directory, "files", "user.json") {
+		t.Fatalf(`result: {%v} but expected {%v}`, path, filepath.Join(directory, "files", "user.json"))
+	}
+
+	for bodyFile, expected := range map[string]string{
+		"files":              "bodyFile {files} does not exist",
+		"files/user.yaml":    "bodyFile {files/user.yaml} does not exist",
+		"../files/user.json": "bodyFile {../files/user.json} is not valid",
+		"/etc/hosts":         "bodyFile {/etc/hosts} is not valid",
+		"":                   "bodyFile {} is not valid",
+	} {
+		if _, err := BodyFilePath(directory, bodyFile); err == nil || err.Error() != expected {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, expected)
+		}
+	}
+}
+
+// TestCheckBodyDirectory calls CheckBodyDirectory(string, []string),
+// checking for a valid return value.
+func TestCheckBodyDirectory(t *testing.T) {
+	protected := []string{"home/requests", "home/mockapic.db", "home/mockapic-ca.key", ""}
+
+	for directory, expected := range map[string]string{
+		"home/files":       "",
+		"files":            "",
+		"home":             "bodyDir {home} cannot contain or be inside {home/requests}",
+		".":                "bodyDir {.} cannot contain or be inside {home/requests}",
+		"home/requests/ns": "bodyDir {home/requests/ns} cannot contain or be inside {home/requests}",
+		"home/../home/./":  "bodyDir {home/../home/./} cannot contain or be inside {home/requests}",
+		"home/mockapic.db": "bodyDir {home/mockapic.db} cannot contain or be inside {home/mockapic.db}",
+	} {
+		err := CheckBodyDirectory(directory, protected)
+		if (expected == "" && err != nil) || (expected != "" && (err == nil || err.Error() != expected)) {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, expected)
+		}
+	}
+}
+
+// TestPurge calls Mock.Purge,
+// checking for a valid return value.
+func TestPurge(t *testing.T) {
+	mocker := NewMockWithStore(NewMemoryStore(), nil, *logger)
+	params := map[string][]string{
+		"status":      {"200"},
+		"contentType": {"text/plain"},
+		"charset":     {"UTF-8"},
+	}
+
+	if _, err := mocker.New(map[string][]string{"ttl": {"-1m"}}, nil); err == nil || err.Error() != "ttl {-1m} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "ttl {-1m} is not valid")
+	}
+	if _, err := mocker.New(map[string][]string{"status": {"200"}, "contentType": {"text/plain"}, "charset": {"UTF-8"}, "expiresAt": {"tomorrow"}}, nil); err == nil || err.Error() != "expiresAt {tomorrow} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "expiresAt {tomorrow} is not valid")
+	}
+
+	persistent, _ := mocker.New(params, nil)
+	params["ttl"] = []string{"15m"}
+	expiring, err := mocker.New(params, nil)
+	if err != nil || expiring.ExpiresAt == "" || expiring.Expired(time.Now()) {
+		t.Fatalf(`result: {%v} but expected {%v}`, expiring, "expires in 15m")
+	}
+
+	if purged, err := mocker.Purge(time.Now()); err != nil || len(purged) != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, purged, "[]")
+	}
+
+	purged, err := mocker.Purge(time.Now().Add(16 * time.Minute))
+	if err != nil || len(purged) != 1 || purged[0].Id != expiring.Id {
+		t.Fatalf(`result: {%v} but expected {%v}`, purged, expiring.Id)
+	}
+
+	if values, _ := mocker.List(); len(values) != 1 || values[0].Id != persistent.Id {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, persistent.Id)
+	}
+}
+
 // TestNewWithBadStatus calls Mocker.New,
 // checking for a valid return value.
 func TestNewWithBadStatus(t *testing.T) {
@@ -312,6 +499,86 @@ func TestNewWithBadContentType(t *testing.T) {
 	}
 }
 
+// TestMockedRequestMatcherMatch calls MockedRequestMatcher.Match(*http.Request),
+// checking for a valid return value.
+func TestMockedRequestMatcherMatch(t *testing.T) {
+	matcher := &MockedRequestMatcher{
+		Method:  "get",
+		Query:   map[string]string{"base": "EUR"},
+		Headers: map[string]string{"x-api-version": "2"},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/rates?base=USD&base=EUR", nil)
+	req.Header.Set("X-Api-Version", "2")
+	if !matcher.Match(req) {
+		t.Fatalf(`result: {%v} but expected {%v}`, false, true)
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/v1/rates?base=EUR", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/rates?base=USD", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/rates?base=EUR", nil),
+	} {
+		if matcher.Match(req) {
+			t.Fatalf(`result: {%v} but expected {%v}`, true, false)
+		}
+	}
+
+	var nilMatcher *MockedRequestMatcher
+	if !nilMatcher.Match(req) {
+		t.Fatalf(`result: {%v} but expected {%v}`, false, true)
+	}
+
+	req.ProtoMajor, req.ProtoMinor = 2, 0
+	for protocol, expected := range map[string]bool{"HTTP/2": true, "2": true, "http/2.0": true, "HTTP/1.1": false, "1.1": false} {
+		if r := (&MockedRequestMatcher{Protocol: protocol}).Match(req); r != expected {
+			t.Fatalf(`result: %s => {%v} but expected {%v}`, protocol, r, expected)
+		}
+	}
+}
+
+// TestMockedRequestMatcherMatchClientCertificate calls MockedRequestMatcher.Match(*http.Request) with a client certificate,
+// checking for a valid return value.
+func TestMockedRequestMatcherMatchClientCertificate(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "partner", Organization: []string{"acme"}},
+		DNSNames:    []string{"partner.acme.com"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/partner", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+
+	for _, matcher := range []*MockedRequestMatcher{
+		{ClientSubject: "partner"},
+		{ClientSubject: "CN=partner,O=acme"},
+		{ClientSAN: "partner.acme.com"},
+		{ClientSubject: "partner", ClientSAN: "10.0.0.1"},
+	} {
+		if !matcher.Match(req) {
+			t.Fatalf(`result: %v => {%v} but expected {%v}`, matcher, false, true)
+		}
+	}
+
+	for _, matcher := range []*MockedRequestMatcher{
+		{ClientSubject: "other"},
+		{ClientSAN: "other.acme.com"},
+	} {
+		if matcher.Match(req) {
+			t.Fatalf(`result: %v => {%v} but expected {%v}`, matcher, true, false)
+		}
+	}
+	if (&MockedRequestMatcher{ClientSubject: "partner"}).Match(httptest.NewRequest(http.MethodGet, "/v1/partner", nil)) {
+		t.Fatalf(`result: {%v} but expected {%v}`, true, false)
+	}
+
+	mock, err := NewMock(workingDirectory, nil, *logger).parse(map[string][]string{
+		"status": {"200"}, "contentType": {"text/plain"}, "charset": {"UTF-8"}, "clientSubject": {"partner"}, "clientSan": {"partner.acme.com"},
+	}, nil)
+	if err != nil || mock.Request == nil || mock.Request.ClientSubject != "partner" || mock.Request.ClientSAN != "partner.acme.com" || len(mock.Headers) != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, mock, "partner")
+	}
+}
+
 // TestNewMockedRequestFromHttpCode calls NewMockedRequestFromHttpCode,
 // checking for a valid return value.
 func TestNewMockedRequestFromHttpCode(t *testing.T) {
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joakim-ribier/go-utils/pkg/iosutil"
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+)
+
+// predefined represents the predefined mocked requests, swapped atomically on reload
+type predefined struct {
+	mu     sync.RWMutex
+	values []PredefinedMockedRequest
+}
+
+func (p *predefined) get() []PredefinedMockedRequest {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.values
+}
+
+func (p *predefined) set(values []PredefinedMockedRequest) []PredefinedMockedRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	previous := p.values
+	p.values = values
+	return previous
+}
+
+// LoadPredefined loads the predefined mocked requests of the {sources}, each source is a *.json or *.yaml file,
+// a glob pattern or a directory of *.json and *.yaml files, the sources which do not exist are ignored.
+// The same identifier, or the same path with the same request matcher, defined twice is an error.
+// It returns the mocked requests and the loaded files.
+func LoadPredefined(sources []string) ([]PredefinedMockedRequest, []string, error) {
+	files, err := predefinedFiles(sources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := []PredefinedMockedRequest{}
+	ids, paths := map[string]PredefinedMockedRequest{}, map[string][]PredefinedMockedRequest{}
+	for _, file := range files {
+		data, err := iosutil.Load(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("file {%s} cannot be read: %v", file, err)
+		}
+		mocks, err := UnmarshalPredefined(data, FileFormat(file))
+		if err != nil {
+			return nil, nil, fmt.Errorf("file {%s} cannot be parsed: %v", file, err)
+		}
+
+		for _, mock := range mocks {
+			mock.Source = file
+			if previous, ok := ids[mock.Id]; ok {
+				return nil, nil, fmt.Errorf("mock {%s} is defined in {%s} and {%s}", mock.Id, previous.Source, file)
+			}
+			// a path can be bound to several mocked requests which match different requests
+			for _, previous := range paths[mock.Path] {
+				if mock.Path != "" && previous.Request.Equal(mock.Request) {
+					return nil, nil, fmt.Errorf("path {%s} is defined by {%s} in {%s} and {%s} in {%s}", mock.Path, previous.Id, previous.Source, mock.Id, file)
+				}
+			}
+			ids[mock.Id], paths[mock.Path] = mock, append(paths[mock.Path], mock)
+			values = append(values, mock)
+		}
+	}
+	return values, files, nil
+}
+
+// PredefinedFingerprint returns a value which changes when a file of the {sources} is created, modified or removed.
+func PredefinedFingerprint(sources []string) string {
+	files, _ := predefinedFiles(sources)
+
+	var fingerprint strings.Builder
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			fmt.Fprintf(&fingerprint, "%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return fingerprint.String()
+}
+
+// predefinedFiles returns the existing files of the {sources}, sorted by name in the directories and the glob patterns.
+func predefinedFiles(sources []string) ([]string, error) {
+	files := []string{}
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if strings.ContainsAny(source, "*?[") {
+			matches, err := filepath.Glob(source)
+			if err != nil {
+				return nil, fmt.Errorf("pattern {%s} is not valid: %v", source, err)
+			}
+			sort.Strings(matches)
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && !info.IsDir() {
+					files = append(files, match)
+				}
+			}
+			continue
+		}
+
+		info, err := os.Stat(source)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, source)
+			continue
+		}
+
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return nil, err
+		}
+		names := []string{}
+		for _, e := range entries {
+			if !e.IsDir() && slicesutil.Exist([]string{".json", ".yaml", ".yml"}, filepath.Ext(e.Name())) {
+				names = append(names, filepath.Join(source, e.Name()))
+			}
+		}
+		sort.Strings(names)
+		files = append(files, names...)
+	}
+	return slicesutil.Distinct(files), nil
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadPredefined calls LoadPredefined and PredefinedFingerprint,
+// checking for a valid return value.
+func TestLoadPredefined(t *testing.T) {
+	directory := t.TempDir()
+	file := filepath.Join(directory, "mockapic.json")
+	os.WriteFile(file, []byte(`[{"id":"id-1","status":200,"contentType":"text/plain","charset":"UTF-8","path":"/one"}]`), 0644)
+	os.MkdirAll(filepath.Join(directory, "upstreams"), os.ModePerm)
+	os.WriteFile(filepath.Join(directory, "upstreams", "b.json"), []byte(`[{"id":"id-3","status":200,"contentType":"text/plain","charset":"UTF-8"}]`), 0644)
+	os.WriteFile(filepath.Join(directory, "upstreams", "a.json"), []byte(`[{"id":"id-2","status":200,"contentType":"text/plain","charset":"UTF-8"}]`), 0644)
+	os.WriteFile(filepath.Join(directory, "upstreams", "README.md"), []byte(`# upstreams`), 0644)
+
+	sources := []string{file, filepath.Join(directory, "upstreams"), filepath.Join(directory, "does-not-exist.json")}
+	values, files, err := LoadPredefined(sources)
+	if err != nil || len(values) != 3 || values[0].Id != "id-1" || values[1].Id != "id-2" || values[2].Id != "id-3" || len(files) != 3 {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, []string{"id-1", "id-2", "id-3"})
+	}
+
+	fingerprint := PredefinedFingerprint(sources)
+	if fingerprint != PredefinedFingerprint(sources) {
+		t.Fatalf(`result: {%v} but expected {%v}`, PredefinedFingerprint(sources), fingerprint)
+	}
+
+	os.WriteFile(filepath.Join(directory, "upstreams", "c.json"), []byte(`[{"id":"id-4"`), 0644)
+	if fingerprint == PredefinedFingerprint(sources) {
+		t.Fatalf(`result: {%v} but expected a new fingerprint`, fingerprint)
+	}
+
+	if _, _, err := LoadPredefined(sources); err == nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "error")
+	}
+}
+
+// TestLoadPredefinedWithGlobAndDuplicates calls LoadPredefined,
+// checking for a valid return value.
+func TestLoadPredefinedWithGlobAndDuplicates(t *testing.T) {
+	directory := t.TempDir()
+	os.WriteFile(filepath.Join(directory, "b.json"), []byte(`[{"id":"id-2","status":200,"contentType":"text/plain","charset":"UTF-8","path":"/two"}]`), 0644)
+	os.WriteFile(filepath.Join(directory, "a.json"), []byte(`[{"id":"id-1","status":200,"contentType":"text/plain","charset":"UTF-8","path":"/one"}]`), 0644)
+
+	// the same file matched twice is loaded once
+	values, files, err := LoadPredefined([]string{filepath.Join(directory, "*.json"), " " + filepath.Join(directory, "a.json")})
+	if err != nil || len(values) != 2 || values[0].Id != "id-1" || values[1].Id != "id-2" || len(files) != 2 {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, []string{"id-1", "id-2"})
+	}
+	if values[0].Source != filepath.Join(directory, "a.json") || values[0].ToMockedRequest().Source != values[0].Source {
+		t.Fatalf(`result: {%v} but expected {%v}`, values[0].Source, filepath.Join(directory, "a.json"))
+	}
+
+	os.WriteFile(filepath.Join(directory, "c.json"), []byte(`[{"id":"id-1","status":200,"contentType":"text/plain","charset":"UTF-8"}]`), 0644)
+	expected := "mock {id-1} is defined in {" + filepath.Join(directory, "a.json") + "} and {" + filepath.Join(directory, "c.json") + "}"
+	if _, _, err := LoadPredefined([]string{filepath.Join(directory, "*.json")}); err == nil || err.Error() != expected {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, expected)
+	}
+
+	os.WriteFile(filepath.Join(directory, "c.json"), []byte(`[{"id":"id-3","status":200,"contentType":"text/plain","charset":"UTF-8","path":"/two"}]`), 0644)
+	expected = "path {/two} is defined by {id-2} in {" + filepath.Join(directory, "b.json") + "} and {id-3} in {" + filepath.Join(directory, "c.json") + "}"
+	if _, _, err := LoadPredefined([]string{filepath.Join(directory, "*.json")}); err == nil || err.Error() != expected {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, expected)
+	}
+
+	// the same path is allowed with different request matchers
+	os.WriteFile(filepath.Join(directory, "b.json"), []byte(`[{"id":"id-2","status":200,"contentType":"text/plain","charset":"UTF-8","path":"/two","request":{"method":"GET"}}]`), 0644)
+	os.WriteFile(filepath.Join(directory, "c.json"), []byte(`[{"id":"id-3","status":201,"contentType":"text/plain","charset":"UTF-8","path":"/two","request":{"method":"POST"}}]`), 0644)
+	if values, _, err := LoadPredefined([]string{filepath.Join(directory, "*.json")}); err != nil || len(values) != 3 {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, values, err, 3)
+	}
+
+	os.WriteFile(filepath.Join(directory, "c.json"), []byte(`[{"id":"id-3","status":201,"contentType":"text/plain","charset":"UTF-8","path":"/two","request":{"method":"get"}}]`), 0644)
+	if _, _, err := LoadPredefined([]string{filepath.Join(directory, "*.json")}); err == nil || err.Error() != expected {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, expected)
+	}
+}
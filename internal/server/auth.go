@@ -0,0 +1,150 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+)
+
+var ROLE_READ_ONLY = "ro"
+var ROLE_READ_WRITE = "rw"
+var ROLES = []string{ROLE_READ_ONLY, ROLE_READ_WRITE}
+
+// basicUser represents the password and the role of a basic authentication user
+type basicUser struct {
+	password string
+	role     string
+}
+
+// Auth represents the API keys and the basic authentication users allowed on the management endpoints,
+// each one with a read-only {ro} or a read-write {rw} role
+type Auth struct {
+	keys  map[string]string
+	users map[string]basicUser
+}
+
+// NewAuth creates and initializes an {Auth} struct without credentials
+func NewAuth() *Auth {
+	return &Auth{keys: map[string]string{}, users: map[string]basicUser{}}
+}
+
+// ParseAuth parses the {apiKeys} formatted as {key}[:{role}],... and the {basicAuth} users
+// formatted as {user}:{password}[:{role}],..., the default role is {rw}.
+// It returns nil if there are no credentials.
+func ParseAuth(apiKeys, basicAuth string) (*Auth, error) {
+	auth := NewAuth()
+	for _, entry := range slicesutil.FilterByNonEmpty(strings.Split(apiKeys, ",")) {
+		key, role := splitRole(strings.TrimSpace(entry))
+		if err := auth.AddKey(key, role); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range slicesutil.FilterByNonEmpty(strings.Split(basicAuth, ",")) {
+		credentials, role := splitRole(strings.TrimSpace(entry))
+		user, password, ok := strings.Cut(credentials, ":")
+		if !ok {
+			return nil, fmt.Errorf("basic auth {%s} is not valid", user)
+		}
+		if err := auth.AddUser(user, password, role); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(auth.keys) == 0 && len(auth.users) == 0 {
+		return nil, nil
+	}
+	return auth, nil
+}
+
+// splitRole splits the {value} on its last {:role} suffix, the role is {rw} if there is no suffix.
+func splitRole(value string) (string, string) {
+	if i := strings.LastIndex(value, ":"); i >= 0 && slicesutil.Exist(ROLES, value[i+1:]) {
+		return value[:i], value[i+1:]
+	}
+	return value, ROLE_READ_WRITE
+}
+
+// AddKey allows the API {key} with the {role}.
+func (a *Auth) AddKey(key, role string) error {
+	if key == "" || !slicesutil.Exist(ROLES, role) {
+		return fmt.Errorf("api key with role {%s} is not valid", role)
+	}
+	a.keys[key] = role
+	return nil
+}
+
+// AddUser allows the basic authentication {user} with the {role}.
+func (a *Auth) AddUser(user, password, role string) error {
+	if user == "" || password == "" || !slicesutil.Exist(ROLES, role) {
+		return fmt.Errorf("basic auth {%s} with role {%s} is not valid", user, role)
+	}
+	a.users[user] = basicUser{password: password, role: role}
+	return nil
+}
+
+// Role returns the role of the credentials of the request {r},
+// the API key is read from the {X-API-Key} header or from the {Authorization: Bearer} header.
+func (a *Auth) Role(r *http.Request) (string, bool) {
+	if user, password, ok := r.BasicAuth(); ok {
+		if u, exists := a.users[user]; exists && equals(u.password, password) {
+			return u.role, true
+		}
+		return "", false
+	}
+
+	key := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && key == "" {
+		key = bearer
+	}
+	if key == "" {
+		return "", false
+	}
+	for value, role := range a.keys {
+		if equals(value, key) {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+func equals(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// authorize checks the credentials of the request {r} on a management endpoint and writes a 401 or a 403 response if they are not allowed,
+// the read-only role is allowed on the {GET} requests only.
+func (s *HTTPServer) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if s.auth == nil {
+		return true
+	}
+
+	role, ok := s.auth.Role(r)
+	if !ok {
+		s.logger.Info("unauthorized request", "uri", r.RequestURI, "method", r.Method)
+		if len(s.auth.users) > 0 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="mockapic"`)
+		}
+		writeError(w, errors.New("authentication is required"), http.StatusUnauthorized)
+		return false
+	}
+
+	if role == ROLE_READ_ONLY && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.logger.Info("forbidden request", "uri", r.RequestURI, "method", r.Method, "role", role)
+		writeError(w, fmt.Errorf("role {%s} is not allowed to %s {%s}", role, r.Method, r.URL.Path), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// secured wraps the management endpoint {handle} with the authorization of the request.
+func (s *HTTPServer) secured(handle func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authorize(w, r) {
+			handle(w, r)
+		}
+	}
+}
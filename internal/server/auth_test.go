@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestParseAuth calls ParseAuth(string, string),
+// checking for a valid return value.
+func TestParseAuth(t *testing.T) {
+	if auth, err := ParseAuth("", ""); auth != nil || err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, auth, nil)
+	}
+
+	auth, err := ParseAuth("key-1:ro, key:2", "admin:pass:word:rw,viewer:secret:ro")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if auth.keys["key-1"] != ROLE_READ_ONLY || auth.keys["key:2"] != ROLE_READ_WRITE ||
+		auth.users["admin"] != (basicUser{password: "pass:word", role: ROLE_READ_WRITE}) ||
+		auth.users["viewer"] != (basicUser{password: "secret", role: ROLE_READ_ONLY}) {
+		t.Fatalf(`result: {%v} but expected {%v}`, auth, "key-1, key:2, admin and viewer")
+	}
+
+	if _, err := ParseAuth("", "admin"); err == nil || err.Error() != "basic auth {admin} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "basic auth {admin} is not valid")
+	}
+	if _, err := ParseAuth(":ro", ""); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+}
+
+// TestAuthorize calls HTTPServer.authorize(http.ResponseWriter, *http.Request) on the management endpoints,
+// checking for a valid return value.
+func TestAuthorize(t *testing.T) {
+	auth, _ := ParseAuth("read-key:ro,write-key:rw", "admin:secret")
+	mocker := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, mocker, *logger, "test").
+		WithNamespaces(NewNamespaces(internal.NewMemoryStorage(*logger), -1, *logger)).
+		WithAuth(auth).
+		handler()
+
+	newMock := "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hello"
+
+	if w := serve(t, handler, http.MethodPost, newMock, "Hello World", nil, http.StatusUnauthorized); w.Header().Get("WWW-Authenticate") != `Basic realm="mockapic"` {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Header(), "WWW-Authenticate")
+	}
+	serve(t, handler, http.MethodPost, newMock, "Hello World", http.Header{"X-Api-Key": {"bad-key"}}, http.StatusUnauthorized)
+	serve(t, handler, http.MethodPost, newMock, "Hello World", http.Header{"X-Api-Key": {"read-key"}}, http.StatusForbidden)
+	serve(t, handler, http.MethodPost, newMock, "Hello World", http.Header{"X-Api-Key": {"write-key"}}, http.StatusCreated)
+	serve(t, handler, http.MethodPost, newMock, "Hello World", http.Header{"Authorization": {"Bearer write-key"}}, http.StatusCreated)
+
+	serve(t, handler, http.MethodGet, "/v1/list", "Hello World", nil, http.StatusUnauthorized)
+	serve(t, handler, http.MethodGet, "/v1/list", "Hello World", http.Header{"X-Api-Key": {"read-key"}}, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/v1/journal", "Hello World", http.Header{"Authorization": {"Basic YWRtaW46c2VjcmV0"}}, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/v1/journal", "Hello World", http.Header{"Authorization": {"Basic YWRtaW46YmFk"}}, http.StatusUnauthorized)
+	serve(t, handler, http.MethodDelete, "/v1/journal", "Hello World", http.Header{"X-Api-Key": {"read-key"}}, http.StatusForbidden)
+
+	// the authenticated callers export all the mocked requests without owner token
+	if w := serve(t, handler, http.MethodGet, "/v1/export", "", http.Header{"X-Api-Key": {"read-key"}}, http.StatusOK); strings.Count(w.Body.String(), `"path"`) != 2 {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Body.String(), 2)
+	}
+
+	// the namespaces cannot be created without credentials
+	serve(t, handler, http.MethodPut, "/ns/team-a", "Hello World", nil, http.StatusUnauthorized)
+	serve(t, handler, http.MethodPost, "/ns/team-a"+newMock, "Hello World", nil, http.StatusUnauthorized)
+	serve(t, handler, http.MethodGet, "/ns/team-a", "Hello World", http.Header{"X-Api-Key": {"read-key"}}, http.StatusNotFound)
+	serve(t, handler, http.MethodPost, "/ns/team-a"+newMock, "Hello World", http.Header{"X-Api-Key": {"write-key"}}, http.StatusCreated)
+
+	// the mocked requests and the static endpoints remain open
+	serve(t, handler, http.MethodGet, "/v1/hello", "Hello World", nil, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/ns/team-a/v1/hello", "Hello World", nil, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/static/charsets", "Hello World", nil, http.StatusOK)
+}
@@ -0,0 +1,263 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var SSL_HOSTS = []string{"localhost", "127.0.0.1", "::1"}
+var CA_VALIDITY = 10 * 365 * 24 * time.Hour
+var CERTIFICATE_VALIDITY = 825 * 24 * time.Hour
+
+var CLIENT_AUTH_REQUIRE = "require"
+var CLIENT_AUTH_VERIFY_IF_GIVEN = "verify_if_given"
+var CLIENT_AUTHS = []string{CLIENT_AUTH_REQUIRE, CLIENT_AUTH_VERIFY_IF_GIVEN}
+
+// WithHosts defines the DNS names and the IP addresses (SANs) of the generated server certificate
+func (s SSL) WithHosts(hosts []string) SSL {
+	s.hosts = hosts
+	return s
+}
+
+// WithPort serves the https requests on the {port} along with the http requests on the server port
+func (s SSL) WithPort(port string) SSL {
+	s.port = port
+	return s
+}
+
+// WithClientCA verifies the client certificates (mTLS) with the {clientCAFile} CA,
+// the certificate is required ({require}) or verified if given ({verify_if_given})
+func (s SSL) WithClientCA(clientCAFile, clientAuth string) SSL {
+	s.clientCAFile = clientCAFile
+	s.clientAuth = clientAuth
+	return s
+}
+
+// TLSConfig returns the TLS configuration verifying the client certificates, or nil if there is no client CA.
+func (s SSL) TLSConfig() (*tls.Config, error) {
+	if s.clientCAFile == "" {
+		return nil, nil
+	}
+
+	clientAuth := tls.RequireAndVerifyClientCert
+	switch s.clientAuth {
+	case "", CLIENT_AUTH_REQUIRE:
+	case CLIENT_AUTH_VERIFY_IF_GIVEN:
+		clientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("client auth {%s} is not valid", s.clientAuth)
+	}
+
+	data, err := os.ReadFile(s.clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("client ca {%s} is not valid", s.clientCAFile)
+	}
+	return &tls.Config{ClientCAs: clientCAs, ClientAuth: clientAuth}, nil
+}
+
+// caFiles returns the CA certificate and key files stored next to the server certificate
+func (s SSL) caFiles() (string, string) {
+	directory := filepath.Dir(s.crtFile)
+	return filepath.Join(directory, "mockapic-ca.crt"), filepath.Join(directory, "mockapic-ca.key")
+}
+
+// Files returns the certificate and key files of the server and of the local CA.
+func (s SSL) Files() []string {
+	caFile, caKeyFile := s.caFiles()
+	return []string{s.crtFile, s.keyFile, caFile, caKeyFile}
+}
+
+// GenerateIfNotExists generates a local CA (if it does not exist) and a server certificate signed by this CA
+// if neither the server certificate nor its key exists, and returns true if the certificate has been generated,
+// an existing file is never overwritten: only one file of a pair returns an error.
+func (s SSL) GenerateIfNotExists() (bool, error) {
+	exists, err := pairExists(s.crtFile, s.keyFile)
+	if err != nil || exists {
+		return false, err
+	}
+
+	ca, caKey, err := s.loadOrGenerateCA()
+	if err != nil {
+		return false, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return false, err
+	}
+
+	template, err := newCertificateTemplate("mockapic", CERTIFICATE_VALIDITY)
+	if err != nil {
+		return false, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, host := range s.hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return false, err
+	}
+	if err := writeCertificate(s.crtFile, s.keyFile, der, key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// loadOrGenerateCA loads the local CA or generates it if it does not exist.
+func (s SSL) loadOrGenerateCA() (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	caFile, caKeyFile := s.caFiles()
+
+	exists, err := pairExists(caFile, caKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists {
+		crtData, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		keyData, err := os.ReadFile(caKeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		crtBlock, _ := pem.Decode(crtData)
+		keyBlock, _ := pem.Decode(keyData)
+		if crtBlock == nil || keyBlock == nil {
+			return nil, nil, fmt.Errorf("ca {%s} is not valid", caFile)
+		}
+		ca, err := x509.ParseCertificate(crtBlock.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		caKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("ca key {%s} is not an ECDSA key", caKeyFile)
+		}
+		return ca, caKey, nil
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := newCertificateTemplate("mockapic local CA", CA_VALIDITY)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writeCertificate(caFile, caKeyFile, der, caKey); err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(der)
+	return ca, caKey, err
+}
+
+// newCertificateTemplate creates a certificate template with a random serial number valid from now for {validity}.
+func newCertificateTemplate(commonName string, validity time.Duration) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{Organization: []string{"mockapic"}, CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+// writeCertificate writes the PEM certificate {der} and its private {key} (readable only by the owner),
+// the files are created and an existing file is never overwritten.
+func writeCertificate(crtFile, keyFile string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(crtFile), 0755); err != nil {
+		return err
+	}
+	if err := createFile(crtFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return err
+	}
+	return createFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600)
+}
+
+// createFile creates the {file} with the {data}, it returns an error if the file already exists.
+func createFile(file string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// pairExists returns true if both the certificate {crtFile} and its {keyFile} exist,
+// and an error if only one of them exists.
+func pairExists(crtFile, keyFile string) (bool, error) {
+	crtExists, keyExists := fileExists(crtFile), fileExists(keyFile)
+	if crtExists && !keyExists {
+		return false, fmt.Errorf("key file {%s} does not exist but the certificate file {%s} does", keyFile, crtFile)
+	}
+	if keyExists && !crtExists {
+		return false, fmt.Errorf("certificate file {%s} does not exist but the key file {%s} does", crtFile, keyFile)
+	}
+	return crtExists && keyExists, nil
+}
+
+func fileExists(file string) bool {
+	info, err := os.Stat(file)
+	return err == nil && !info.IsDir()
+}
+
+// getCA returns the PEM certificate of the local CA which signs the generated server certificate.
+func (s *HTTPServer) getCA(w http.ResponseWriter, r *http.Request) {
+	caFile, _ := s.ssl.caFiles()
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		writeError(w, errors.New("ca certificate is not available"), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
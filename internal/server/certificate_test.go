@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestGenerateIfNotExists calls SSL.GenerateIfNotExists,
+// checking for a valid return value.
+func TestGenerateIfNotExists(t *testing.T) {
+	directory := t.TempDir()
+	ssl := NewSSL(true, directory, "", "").WithHosts([]string{"localhost", "mockapic.local", "127.0.0.1"})
+
+	generated, err := ssl.GenerateIfNotExists()
+	if err != nil || !generated {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, generated, err, true)
+	}
+	for _, file := range []string{"mockapic.crt", "mockapic.key", "mockapic-ca.crt", "mockapic-ca.key"} {
+		if !fileExists(filepath.Join(directory, file)) {
+			t.Fatalf(`result: {%v} but expected {%v}`, false, file)
+		}
+	}
+	if info, _ := os.Stat(filepath.Join(directory, "mockapic.key")); info.Mode().Perm() != 0600 {
+		t.Fatalf(`result: {%v} but expected {%v}`, info.Mode().Perm(), "0600")
+	}
+
+	pair, err := tls.LoadX509KeyPair(filepath.Join(directory, "mockapic.crt"), filepath.Join(directory, "mockapic.key"))
+	if err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+	}
+	certificate, _ := x509.ParseCertificate(pair.Certificate[0])
+	ca, _ := os.ReadFile(filepath.Join(directory, "mockapic-ca.crt"))
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca)
+	for _, host := range []string{"localhost", "mockapic.local", "127.0.0.1"} {
+		if _, err := certificate.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, host)
+		}
+	}
+	if _, err := certificate.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots}); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+
+	// the existing certificate is kept
+	crt, _ := os.ReadFile(filepath.Join(directory, "mockapic.crt"))
+	if generated, err := ssl.GenerateIfNotExists(); err != nil || generated {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, generated, err, false)
+	}
+
+	// an existing certificate is never overwritten if its key does not exist
+	os.Remove(filepath.Join(directory, "mockapic.key"))
+	if generated, err := ssl.GenerateIfNotExists(); err == nil || generated {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, generated, err, "error")
+	}
+	if kept, _ := os.ReadFile(filepath.Join(directory, "mockapic.crt")); !bytes.Equal(crt, kept) {
+		t.Fatalf(`result: {%v} but expected {%v}`, "new certificate", "same certificate")
+	}
+
+	// the existing CA signs the new certificate
+	os.Remove(filepath.Join(directory, "mockapic.crt"))
+	if generated, err := ssl.GenerateIfNotExists(); err != nil || !generated {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, generated, err, true)
+	}
+	newCrt, _ := os.ReadFile(filepath.Join(directory, "mockapic.crt"))
+	newCA, _ := os.ReadFile(filepath.Join(directory, "mockapic-ca.crt"))
+	if bytes.Equal(crt, newCrt) || !bytes.Equal(ca, newCA) {
+		t.Fatalf(`result: {%v} but expected {%v}`, "same certificate or new CA", "new certificate signed by the same CA")
+	}
+
+	// an existing CA certificate is never overwritten if its key does not exist
+	other := NewSSL(true, t.TempDir(), "", "")
+	otherCA, _ := other.caFiles()
+	os.WriteFile(otherCA, ca, 0644)
+	if generated, err := other.GenerateIfNotExists(); err == nil || generated {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, generated, err, "error")
+	}
+	if kept, _ := os.ReadFile(otherCA); !bytes.Equal(ca, kept) {
+		t.Fatalf(`result: {%v} but expected {%v}`, "new CA", "same CA")
+	}
+	if fileExists(filepath.Join(filepath.Dir(otherCA), "mockapic.crt")) {
+		t.Fatalf(`result: {%v} but expected {%v}`, "generated certificate", "no certificate")
+	}
+
+	// a file is never overwritten on its creation
+	if err := createFile(otherCA, []byte("new"), 0644); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+}
+
+// TestGetCAEndpoint calls HTTPServer.getCA(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestGetCAEndpoint(t *testing.T) {
+	call := func(ssl SSL, expectedStatusCode int) *httptest.ResponseRecorder {
+		return serve(t, NewHTTPServer("{port}", ssl, workingDirectory, -1, &MockerTest{}, *logger, "test").handler(), http.MethodGet, "/static/ca.pem", "", nil, expectedStatusCode)
+	}
+
+	directory := t.TempDir()
+	ssl := NewSSL(true, directory, "", "")
+	call(ssl, http.StatusNotFound)
+
+	ssl.GenerateIfNotExists()
+	w := call(ssl, http.StatusOK)
+	if w.Header().Get("Content-Type") != "application/x-pem-file" || !bytes.HasPrefix(w.Body.Bytes(), []byte("-----BEGIN CERTIFICATE-----")) {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Body.String(), "CA certificate")
+	}
+}
+
+// TestTLSConfig calls SSL.TLSConfig,
+// checking for a valid return value.
+func TestTLSConfig(t *testing.T) {
+	directory := t.TempDir()
+	ssl := NewSSL(true, directory, "", "")
+	ssl.GenerateIfNotExists()
+	caFile, _ := ssl.caFiles()
+
+	if config, err := ssl.TLSConfig(); err != nil || config != nil {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, config, err, nil)
+	}
+	if config, err := ssl.WithClientCA(caFile, "").TLSConfig(); err != nil || config.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, config, err, tls.RequireAndVerifyClientCert)
+	}
+	if config, err := ssl.WithClientCA(caFile, CLIENT_AUTH_VERIFY_IF_GIVEN).TLSConfig(); err != nil || config.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, config, err, tls.VerifyClientCertIfGiven)
+	}
+	if _, err := ssl.WithClientCA(caFile, "optional").TLSConfig(); err == nil || err.Error() != "client auth {optional} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "client auth {optional} is not valid")
+	}
+	if _, err := ssl.WithClientCA(filepath.Join(directory, "mockapic.key"), "").TLSConfig(); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+}
+
+// TestMutualTLS calls a mocked request matching the client certificate on a mTLS server,
+// checking for a valid return value.
+func TestMutualTLS(t *testing.T) {
+	directory := t.TempDir()
+	ssl := NewSSL(true, directory, "", "")
+	ca, caKey, err := ssl.loadOrGenerateCA()
+	if err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+	}
+	caFile, _ := ssl.caFiles()
+
+	newClientCertificate := func(commonName string) tls.Certificate {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template, _ := newCertificateTemplate(commonName, time.Hour)
+		template.DNSNames = []string{commonName + ".acme.com"}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		der, _ := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+
+	for _, clientAuth := range CLIENT_AUTHS {
+		mock := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+		s := NewHTTPServer("{port}", ssl.WithClientCA(caFile, clientAuth), workingDirectory, -1, mock, *logger, "test")
+		created, _ := mock.New(map[string][]string{"status": {"200"}, "contentType": {"text/plain"}, "charset": {"UTF-8"}, "path": {"/partner"}, "clientSubject": {"partner"}}, []byte("partner"))
+		s.Routes.Set("/v1/partner", created.Id)
+
+		server := httptest.NewUnstartedServer(s.handler())
+		server.TLS, _ = s.ssl.TLSConfig()
+		server.StartTLS()
+		defer server.Close()
+
+		call := func(certificates []tls.Certificate) (int, error) {
+			client := server.Client()
+			client.CloseIdleConnections() // a new handshake presents the certificates
+			client.Transport.(*http.Transport).TLSClientConfig.Certificates = certificates
+			resp, err := client.Get(server.URL + "/v1/partner")
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
+			return resp.StatusCode, nil
+		}
+
+		statusCode, err := call(nil)
+		if clientAuth == CLIENT_AUTH_REQUIRE && err == nil {
+			t.Fatalf(`result: {%v} but expected {%v}`, statusCode, "handshake error")
+		}
+		if clientAuth == CLIENT_AUTH_VERIFY_IF_GIVEN && statusCode != http.StatusNotFound {
+			t.Fatalf(`result: {%v} {%v} but expected {%v}`, statusCode, err, http.StatusNotFound)
+		}
+		if statusCode, err := call([]tls.Certificate{newClientCertificate("other")}); err != nil || statusCode != http.StatusNotFound {
+			t.Fatalf(`result: {%v} {%v} but expected {%v}`, statusCode, err, http.StatusNotFound)
+		}
+		if statusCode, err := call([]tls.Certificate{newClientCertificate("partner")}); err != nil || statusCode != http.StatusOK {
+			t.Fatalf(`result: {%v} {%v} but expected {%v}`, statusCode, err, http.StatusOK)
+		}
+
+		// the presented certificate is recorded in the journal
+		entry := s.journal.Entries()[0]
+		if entry.ClientCert == nil || entry.ClientCert.Subject != "CN=partner,O=mockapic" ||
+			entry.ClientCert.Issuer != "CN=mockapic local CA,O=mockapic" || entry.ClientCert.SANs[0] != "partner.acme.com" || len(entry.ClientCert.Fingerprint) != 64 {
+			t.Fatalf(`result: {%v} but expected {%v}`, entry.ClientCert, "partner")
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/mockapic/internal"
+	"github.com/joakim-ribier/mockapic/pkg"
+)
+
+// checkBearer returns {401} if the bearer token of the request {r} is missing, not issued or expired,
+// {403} if it does not have the scopes required by the {auth}, or {0}.
+func (s *HTTPServer) checkBearer(w http.ResponseWriter, r *http.Request, auth *internal.MockedRequestAuth) int {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mockapic"`)
+		return http.StatusUnauthorized
+	}
+	if slices.Contains(auth.Values, token) {
+		return 0
+	}
+
+	scope, ok := s.oauth.Validate(token, time.Now())
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mockapic", error="invalid_token"`)
+		return http.StatusUnauthorized
+	}
+	for _, required := range strings.Fields(auth.Scope) {
+		if !slicesutil.Exist(strings.Fields(scope), required) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mockapic", error="insufficient_scope", scope="`+auth.Scope+`"`)
+			return http.StatusForbidden
+		}
+	}
+	return 0
+}
+
+// checkJWT returns {401} if the JWT of the request {r} is missing, not signed by the server keys, expired or not issued for the audience,
+// {403} if it does not have the claims required by the {auth}, or {0}.
+func (s *HTTPServer) checkJWT(w http.ResponseWriter, r *http.Request, auth *internal.MockedRequestAuth) int {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mockapic"`)
+		return http.StatusUnauthorized
+	}
+
+	claims, err := s.jwtKeys.Verify(token)
+	if err != nil {
+		s.logger.Info("jwt not valid", "uri", r.RequestURI, "error", err.Error())
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mockapic", error="invalid_token"`)
+		return http.StatusUnauthorized
+	}
+
+	statusCode := auth.CheckClaims(claims, time.Now())
+	switch statusCode {
+	case http.StatusUnauthorized:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mockapic", error="invalid_token"`)
+	case http.StatusForbidden:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mockapic", error="insufficient_scope"`)
+	}
+	return statusCode
+}
+
+// authenticate checks the credentials of the request {r} if the {mock} simulates an upstream authentication,
+// and returns the mocked request to respond with if they are missing or not valid.
+func (s *HTTPServer) authenticate(w http.ResponseWriter, r *http.Request, sc scope, mock *internal.MockedRequest) *internal.MockedRequest {
+	auth := mock.Auth
+	if auth == nil {
+		return nil
+	}
+
+	statusCode := 0
+	switch auth.Type {
+	case internal.AUTH_BEARER:
+		statusCode = s.checkBearer(w, r, auth)
+	case internal.AUTH_API_KEY:
+		statusCode = auth.CheckAPIKey(r)
+	case internal.AUTH_JWT:
+		statusCode = s.checkJWT(w, r, auth)
+	case internal.AUTH_HMAC:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.logger.Error(err, "error to read body", "uri", r.RequestURI)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		statusCode = auth.CheckHMAC(r, body)
+	}
+	if statusCode == 0 {
+		return nil
+	}
+
+	s.logger.Info("upstream authentication failed", "uri", r.RequestURI, "mockId", mock.Id, "auth", auth.Type, "status", statusCode)
+	if auth.MockId != "" {
+		for _, space := range sc.spaces() {
+			if failed, err := space.mocker.Get(auth.MockId); err == nil {
+				return failed
+			}
+		}
+		s.logger.Info("auth mock not found", "uri", r.RequestURI, "mockId", auth.MockId)
+	}
+
+	failed := internal.NewMockedRequestFromHttpCode(statusCode, pkg.HTTP_CODES[statusCode])
+	return &failed
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var READ_HEADER_TIMEOUT = 10 * time.Second
+var READ_TIMEOUT = 30 * time.Second
+var WRITE_TIMEOUT = 90 * time.Second // longer than the max {delay} of the mocked requests
+var IDLE_TIMEOUT = 120 * time.Second
+var SHUTDOWN_TIMEOUT = 30 * time.Second
+
+var HEALTH_UP = "UP"
+var HEALTH_DOWN = "DOWN"
+
+// Timeouts represents the timeouts of the http servers
+type Timeouts struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// NewTimeouts creates a {Timeouts} struct with the default timeouts
+func NewTimeouts() Timeouts {
+	return Timeouts{ReadHeader: READ_HEADER_TIMEOUT, Read: READ_TIMEOUT, Write: WRITE_TIMEOUT, Idle: IDLE_TIMEOUT}
+}
+
+// HealthStatus represents the status of the server and of each one of its checks
+type HealthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// pinger is implemented by the mockers which can check their storage
+type pinger interface {
+	Ping() error
+}
+
+// Shutdown marks the server as not ready and stops the listeners gracefully:
+// the new connections are refused and the in-flight requests are completed until the {ctx} deadline.
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+
+	s.serversMu.Lock()
+	servers := s.servers
+	s.serversMu.Unlock()
+
+	var errs []error
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// healthz returns the liveness of the server, it is alive as long as it answers.
+func (s *HTTPServer) healthz(w http.ResponseWriter, r *http.Request) {
+	s.writeResponse(w, r, HealthStatus{Status: HEALTH_UP}, http.StatusOK)
+}
+
+// readyz returns the readiness of the server: {503} if it is shutting down,
+// if its storage is not available or if the predefined requests files have never been loaded
+// (a failed reload is reported but the previous predefined requests are still served).
+func (s *HTTPServer) readyz(w http.ResponseWriter, r *http.Request) {
+	checks, ready := map[string]string{"server": HEALTH_UP}, true
+	if s.shuttingDown.Load() {
+		checks["server"], ready = "shutting down", false
+	}
+
+	if p, ok := s.mocker.(pinger); ok {
+		checks["storage"] = HEALTH_UP
+		if err := p.Ping(); err != nil {
+			checks["storage"], ready = err.Error(), false
+		}
+	}
+
+	if s.reloader != nil {
+		checks["predefined"] = HEALTH_UP
+		if status := s.reloader.Status(); status.Error != "" {
+			checks["predefined"] = status.Error
+			ready = ready && status.LoadedAt != ""
+		}
+	}
+
+	health, statusCode := HealthStatus{Status: HEALTH_UP, Checks: checks}, http.StatusOK
+	if !ready {
+		health.Status, statusCode = HEALTH_DOWN, http.StatusServiceUnavailable
+	}
+	s.writeResponse(w, r, health, statusCode)
+}
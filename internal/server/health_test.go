@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestHealthEndpoints calls HTTPServer.healthz and HTTPServer.readyz,
+// checking for a valid return value.
+func TestHealthEndpoints(t *testing.T) {
+	directory := t.TempDir()
+	mock := internal.NewMock(filepath.Join(directory, "requests"), nil, *logger)
+	os.MkdirAll(filepath.Join(directory, "requests"), os.ModePerm)
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), directory, -1, mock, *logger, "test")
+	reloader := NewReloader([]string{filepath.Join(directory, "mockapic.json")}, directory, mock, s.Routes, *logger)
+	handler := s.WithReloader(reloader).handler()
+
+	if health, _ := jsonsutil.Unmarshal[HealthStatus](serve(t, handler, http.MethodGet, "/healthz", "", nil, http.StatusOK).Body.Bytes()); health.Status != HEALTH_UP {
+		t.Fatalf(`result: {%v} but expected {%v}`, health, HEALTH_UP)
+	}
+	if health, _ := jsonsutil.Unmarshal[HealthStatus](serve(t, handler, http.MethodGet, "/readyz", "", nil, http.StatusOK).Body.Bytes()); health.Status != HEALTH_UP || len(health.Checks) != 3 {
+		t.Fatalf(`result: {%v} but expected {%v}`, health, HEALTH_UP)
+	}
+
+	// the predefined requests file is not valid and has never been loaded
+	os.WriteFile(filepath.Join(directory, "mockapic.json"), []byte("{"), 0644)
+	reloader.Reload()
+	if health, _ := jsonsutil.Unmarshal[HealthStatus](serve(t, handler, http.MethodGet, "/readyz", "", nil, http.StatusServiceUnavailable).Body.Bytes()); health.Status != HEALTH_DOWN || health.Checks["predefined"] == HEALTH_UP || health.Checks["storage"] != HEALTH_UP {
+		t.Fatalf(`result: {%v} but expected {%v}`, health, "predefined down")
+	}
+	os.WriteFile(filepath.Join(directory, "mockapic.json"), []byte("[]"), 0644)
+	reloader.Reload()
+
+	// the reload fails but the previous predefined requests are still served
+	os.WriteFile(filepath.Join(directory, "mockapic.json"), []byte("{"), 0644)
+	reloader.Reload()
+	if health, _ := jsonsutil.Unmarshal[HealthStatus](serve(t, handler, http.MethodGet, "/readyz", "", nil, http.StatusOK).Body.Bytes()); health.Status != HEALTH_UP || health.Checks["predefined"] == HEALTH_UP {
+		t.Fatalf(`result: {%v} but expected {%v}`, health, "predefined reload error reported")
+	}
+	os.WriteFile(filepath.Join(directory, "mockapic.json"), []byte("[]"), 0644)
+	reloader.Reload()
+
+	// the storage is not available
+	os.RemoveAll(filepath.Join(directory, "requests"))
+	if health, _ := jsonsutil.Unmarshal[HealthStatus](serve(t, handler, http.MethodGet, "/readyz", "", nil, http.StatusServiceUnavailable).Body.Bytes()); health.Checks["storage"] == HEALTH_UP || health.Checks["predefined"] != HEALTH_UP {
+		t.Fatalf(`result: {%v} but expected {%v}`, health, "storage down")
+	}
+	os.MkdirAll(filepath.Join(directory, "requests"), os.ModePerm)
+
+	// the server is shutting down but still alive
+	s.Shutdown(context.Background())
+	if health, _ := jsonsutil.Unmarshal[HealthStatus](serve(t, handler, http.MethodGet, "/readyz", "", nil, http.StatusServiceUnavailable).Body.Bytes()); health.Checks["server"] != "shutting down" {
+		t.Fatalf(`result: {%v} but expected {%v}`, health, "shutting down")
+	}
+	serve(t, handler, http.MethodGet, "/healthz", "", nil, http.StatusOK)
+}
+
+// TestShutdown calls HTTPServer.Shutdown during a request,
+// checking for a valid return value.
+func TestShutdown(t *testing.T) {
+	l, _ := net.Listen("tcp", ":0")
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+
+	mock := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+	s := NewHTTPServer(port, NewSSL(false, "", "", ""), workingDirectory, -1, mock, *logger, "test")
+	created, _ := mock.New(map[string][]string{"status": {"200"}, "contentType": {"text/plain"}, "charset": {"UTF-8"}}, []byte("Hello World"))
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Listen()
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// the in-flight request is completed
+	responses := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://localhost:" + port + "/v1/" + created.Id + "?delay=300ms")
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responses <- string(body)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+	}
+	if r := <-responses; r != "Hello World" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello World")
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, http.ErrServerClosed)
+	}
+
+	// the new connections are refused
+	if _, err := http.Get("http://localhost:" + port + "/healthz"); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+}
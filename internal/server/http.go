@@ -1,12 +1,19 @@
 package server
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/joakim-ribier/go-utils/pkg/iosutil"
@@ -15,12 +22,17 @@ import (
 	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
 	"github.com/joakim-ribier/go-utils/pkg/stringsutil"
 	"github.com/joakim-ribier/mockapic/internal"
+	"github.com/joakim-ribier/mockapic/internal/importer"
 	"github.com/joakim-ribier/mockapic/pkg"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 var METHODS_ALL = []string{
 	http.MethodDelete,
 	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
 	http.MethodPatch,
 	http.MethodPost,
 	http.MethodPut,
@@ -30,25 +42,53 @@ type SSL struct {
 	enabled bool
 	crtFile string
 	keyFile string
+	hosts   []string
+	port    string
+
+	clientCAFile string
+	clientAuth   string
 }
 
 func NewSSL(enabled bool, directory, crtFile, keyFile string) SSL {
 	return SSL{
 		enabled: enabled,
 		crtFile: stringsutil.OrElse(crtFile, directory+"/mockapic.crt"),
-		keyFile: stringsutil.OrElse(keyFile, directory+"/mockapic.key")}
+		keyFile: stringsutil.OrElse(keyFile, directory+"/mockapic.key"),
+		hosts:   SSL_HOSTS}
 }
 
 // HTTPServer represents a http server struct
 type HTTPServer struct {
 	Port                       string
 	workingDirectory           string
+	bodyDirectory              string
 	ssl                        SSL
 	totalNumberRequestsAllowed int
 
-	mocker internal.Mocker
-
-	PathToMockId map[string]string
+	mocker     internal.Mocker
+	namespaces *Namespaces
+	journal    *Journal
+	sessions   *Sessions
+	sessionTTL time.Duration
+	quotas     *Quotas
+
+	rateLimiter *RateLimiter
+	rateLimits  map[string]*internal.MockedRequestRateLimit
+	reloader    *Reloader
+	auth        *Auth
+	oauth       *OAuth
+	jwtKeys     *JWTKeys
+	listeners   []Listener
+	http2       bool
+	timeouts    Timeouts
+	metrics     *Metrics
+
+	servers      []*http.Server
+	serversMu    sync.Mutex
+	shuttingDown atomic.Bool
+
+	Routes       *Routes
+	remoteAddrMu sync.Mutex
 	logger       logsutil.Logger
 	version      string
 }
@@ -73,22 +113,163 @@ func NewHTTPServer(
 		mocker:                     mocker,
 		ssl:                        ssl,
 		workingDirectory:           workingDirectory,
+		bodyDirectory:              workingDirectory + "/" + internal.BODY_FILES_DIRECTORY,
 		totalNumberRequestsAllowed: totalNumberRequestsAllowed,
 		logger:                     logger.Namespace("server"),
-		PathToMockId:               map[string]string{},
+		Routes:                     NewRoutes(),
+		journal:                    NewJournal(JOURNAL_MAX_ENTRIES),
+		sessions:                   NewSessions(logger),
+		sessionTTL:                 SESSION_TTL,
+		rateLimiter:                NewRateLimiter(),
+		oauth:                      NewOAuth(nil, OAUTH_TOKEN_TTL),
+		jwtKeys:                    NewJWTKeys(),
+		http2:                      true,
+		timeouts:                   NewTimeouts(),
+		metrics:                    NewMetrics(),
 		version:                    version,
 	}
 }
 
-// Listen creates the http server and dispatches the incoming requests
-func (s HTTPServer) Listen() error {
+// WithSessionTTL defines the time-to-live of the sessions without activity
+func (s *HTTPServer) WithSessionTTL(ttl time.Duration) *HTTPServer {
+	s.sessionTTL = ttl
+	return s
+}
+
+// WithQuotas limits the mocked requests created by each client
+func (s *HTTPServer) WithQuotas(quotas *Quotas) *HTTPServer {
+	s.quotas = quotas
+	return s
+}
+
+// WithRateLimits limits the calls to the mocked requests by path prefix (e.g. {/api})
+func (s *HTTPServer) WithRateLimits(rateLimits map[string]*internal.MockedRequestRateLimit) *HTTPServer {
+	s.rateLimits = rateLimits
+	return s
+}
+
+// WithReloader enables the {/v1/predefined} endpoint on the status of the predefined requests {reloader}
+func (s *HTTPServer) WithReloader(reloader *Reloader) *HTTPServer {
+	s.reloader = reloader
+	return s
+}
+
+// WithNamespaces enables the {/ns/{name}/v1/...} endpoints on the provided {namespaces}
+func (s *HTTPServer) WithNamespaces(namespaces *Namespaces) *HTTPServer {
+	s.namespaces = namespaces
+	return s
+}
+
+// WithAuth requires the {auth} credentials on the management endpoints, the mocked requests remain open
+func (s *HTTPServer) WithAuth(auth *Auth) *HTTPServer {
+	s.auth = auth
+	return s
+}
+
+// WithOAuth replaces the local OAuth2 authorization server of the {/oauth/token} endpoint
+func (s *HTTPServer) WithOAuth(oauth *OAuth) *HTTPServer {
+	s.oauth = oauth
+	return s
+}
+
+// WithListeners serves the mocked requests on additional ports, each one bound to a namespace
+func (s *HTTPServer) WithListeners(listeners []Listener) *HTTPServer {
+	s.listeners = listeners
+	return s
+}
+
+// WithHTTP2 enables (default) or disables HTTP/2 on the TLS listeners and h2c on the plain listeners
+func (s *HTTPServer) WithHTTP2(enabled bool) *HTTPServer {
+	s.http2 = enabled
+	return s
+}
+
+// WithTimeouts defines the read, write and idle timeouts of the http servers
+func (s *HTTPServer) WithTimeouts(timeouts Timeouts) *HTTPServer {
+	s.timeouts = timeouts
+	return s
+}
+
+// WithBodyDirectory defines the directory of the body files, the only one where the mocked requests can read files
+func (s *HTTPServer) WithBodyDirectory(directory string) *HTTPServer {
+	s.bodyDirectory = directory
+	return s
+}
+
+// Listen creates the http servers of the listeners and dispatches the incoming requests,
+// it returns the error of the first server which stops ({http.ErrServerClosed} after a {Shutdown}).
+func (s *HTTPServer) Listen() error {
+	server := s.handler()
+	listeners := s.Listeners()
+
+	var tlsConfig *tls.Config
+	if slicesutil.ExistT(listeners, func(l Listener) bool { return l.TLS }) {
+		generated, err := s.ssl.GenerateIfNotExists()
+		if err != nil {
+			return err
+		}
+		if generated {
+			s.logger.Info("self-signed certificate generated", "crt", s.ssl.crtFile, "key", s.ssl.keyFile, "hosts", s.ssl.hosts)
+		}
+		if tlsConfig, err = s.ssl.TLSConfig(); err != nil {
+			return err
+		}
+	}
+
+	go s.janitor(JANITOR_INTERVAL)
+
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		httpServer := s.newServer(listener, server, tlsConfig)
+		s.serversMu.Lock()
+		s.servers = append(s.servers, httpServer)
+		s.serversMu.Unlock()
+		go func(listener Listener) {
+			if listener.TLS {
+				errs <- httpServer.ListenAndServeTLS(s.ssl.crtFile, s.ssl.keyFile)
+			} else {
+				errs <- httpServer.ListenAndServe()
+			}
+		}(listener)
+	}
+	return <-errs
+}
+
+// newServer creates the http server of the {listener} which dispatches the requests to the {handler},
+// with HTTP/2 on TLS and h2c (HTTP/2 cleartext) on plain listeners unless HTTP/2 is disabled.
+func (s *HTTPServer) newServer(listener Listener, handler http.Handler, tlsConfig *tls.Config) *http.Server {
+	if listener.Namespace != "" {
+		handler = s.bind(handler, listener.Namespace)
+	}
+
+	httpServer := &http.Server{
+		Addr:              ":" + listener.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: s.timeouts.ReadHeader,
+		ReadTimeout:       s.timeouts.Read,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+	}
+	switch {
+	case listener.TLS:
+		httpServer.TLSConfig = tlsConfig.Clone()
+		if !s.http2 {
+			// a non-nil empty map disables HTTP/2
+			httpServer.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+		}
+	case s.http2:
+		httpServer.Handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+	return httpServer
+}
+
+// handler creates the handler which dispatches the incoming requests to the endpoints
+func (s *HTTPServer) handler() http.Handler {
 	server := http.NewServeMux()
 
 	handleFuncToMethods := func(methods []string, pattern string, handle func(w http.ResponseWriter, r *http.Request)) {
 		server.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
-			remoteAddr := s.findRemoteAddr(r.RemoteAddr)
-			s.logger.Info("request", "uri", r.RequestURI, "method", r.Method, "remoteAddr", remoteAddr)
-			fmt.Printf("%s [%s] %s\n", remoteAddr, r.Method, r.RequestURI)
+			s.logRequest(r)
 
 			if !slicesutil.Exist(methods, r.Method) {
 				w.WriteHeader(404)
@@ -103,29 +284,70 @@ func (s HTTPServer) Listen() error {
 	}
 
 	handleFunc(http.MethodGet, "/", s.home)
+	handleFunc(http.MethodGet, "/healthz", s.healthz)
+	handleFunc(http.MethodGet, "/readyz", s.readyz)
+	handleFunc(http.MethodGet, "/metrics", s.secured(s.getMetrics))
 
 	handleFunc(http.MethodGet, "/static/content-types", s.getContentTypes)
 	handleFunc(http.MethodGet, "/static/charsets", s.getCharsets)
 	handleFunc(http.MethodGet, "/static/status-codes", s.getStatusCodes)
+	handleFunc(http.MethodGet, "/static/ca.pem", s.getCA)
 
 	handleFuncToMethods(METHODS_ALL, "/v1/", s.getMockedRequest)
-	handleFunc(http.MethodGet, "/v1/raw/", s.getMockedRequestRaw)
-	handleFunc(http.MethodGet, "/v1/list", s.list)
-	handleFunc(http.MethodPost, "/v1/new", s.addNewMock)
-
-	if s.ssl.enabled {
-		return http.ListenAndServeTLS(
-			":"+s.Port,
-			s.ssl.crtFile,
-			s.ssl.keyFile,
-			server,
-		)
-	} else {
-		return http.ListenAndServe(":"+s.Port, server)
+	handleFunc(http.MethodPost, "/oauth/token", s.oauthToken)
+	handleFunc(http.MethodPost, "/jwt/token", s.jwtToken)
+	handleFunc(http.MethodGet, "/.well-known/jwks.json", s.jwks)
+	handleFunc(http.MethodGet, "/.well-known/openid-configuration", s.openIDConfiguration)
+	handleFuncToMethods([]string{http.MethodGet, http.MethodPut, http.MethodDelete}, "/v1/raw/", s.secured(s.mockedRequestRaw))
+	handleFunc(http.MethodGet, "/v1/list", s.secured(s.list))
+	handleFunc(http.MethodGet, "/v1/export", s.secured(s.export))
+	handleFunc(http.MethodPost, "/v1/new", s.secured(s.addNewMock))
+	handleFunc(http.MethodPost, "/v1/import/", s.secured(s.importMocks))
+	handleFuncToMethods([]string{http.MethodGet, http.MethodDelete}, "/v1/journal", s.secured(s.journalEntries))
+	handleFunc(http.MethodGet, "/v1/predefined", s.secured(s.getPredefinedStatus))
+	handleFunc(http.MethodPost, "/v1/sessions", s.secured(s.openSession))
+	handleFuncToMethods([]string{http.MethodGet, http.MethodDelete}, "/v1/sessions/", s.secured(s.getOrCloseSession))
+
+	server.HandleFunc("/session/", func(w http.ResponseWriter, r *http.Request) {
+		s.serveSession(server, w, r)
+	})
+
+	handleFunc(http.MethodGet, "/ns", s.secured(s.listNamespaces))
+	server.HandleFunc("/ns/", func(w http.ResponseWriter, r *http.Request) {
+		s.serveNamespace(server, w, r)
+	})
+
+	return server
+}
+
+func (s *HTTPServer) logRequest(r *http.Request) {
+	remoteAddr := s.findRemoteAddr(r.RemoteAddr)
+	s.logger.Info("request", "uri", r.RequestURI, "method", r.Method, "remoteAddr", remoteAddr)
+	fmt.Printf("%s [%s] %s\n", remoteAddr, r.Method, r.RequestURI)
+}
+
+// namespace returns the namespace bound to the request {r} or the default one.
+func (s *HTTPServer) namespace(r *http.Request) *Namespace {
+	if ns, ok := r.Context().Value(namespaceContextKey{}).(*Namespace); ok {
+		return ns
 	}
+	return s.defaultNamespace()
 }
 
-func (s HTTPServer) home(w http.ResponseWriter, r *http.Request) {
+// defaultNamespace returns the namespace of the requests without {/ns/{name}} prefix.
+func (s *HTTPServer) defaultNamespace() *Namespace {
+	return &Namespace{
+		mockSpace: mockSpace{
+			mocker:  s.mocker,
+			routes:  s.Routes,
+			journal: s.journal,
+		},
+		sessions:                   s.sessions,
+		totalNumberRequestsAllowed: s.totalNumberRequestsAllowed,
+	}
+}
+
+func (s *HTTPServer) home(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(200)
 
@@ -151,20 +373,53 @@ func (s HTTPServer) home(w http.ResponseWriter, r *http.Request) {
 		t.AppendSeparator()
 		t.AppendRows([]table.Row{
 			{"GET", "/", "Get info"},
+			{"GET", "/healthz", "Get the liveness of the server"},
+			{"GET", "/readyz", "Get the readiness of the server (storage, predefined requests)"},
+			{"GET", "/metrics", "Get the metrics of the server (Prometheus text format)"},
 		})
 		t.AppendSeparator()
 		t.AppendRows([]table.Row{
 			{"GET", "/static/content-types", "Get allowed content types"},
 			{"GET", "/static/charsets", "Get allowed charsets"},
 			{"GET", "/static/status-codes", "Get allowed status codes"},
+			{"GET", "/static/ca.pem", "Get the CA certificate of the generated server certificate"},
+		})
+		t.AppendSeparator()
+		t.AppendRows([]table.Row{
+			{"POST", "/oauth/token", "Issue OAuth2 tokens (client credentials, refresh token)"},
+			{"POST", "/jwt/token", "Mint a JWT signed with the RS256 or ES256 key"},
+			{"GET", "/.well-known/jwks.json", "Get the public keys of the signed JWT"},
+			{"GET", "/.well-known/openid-configuration", "Get the OpenID Connect discovery document"},
 		})
 		t.AppendSeparator()
 		t.AppendRows([]table.Row{
 			{"ALL", "/v1/{id}", "Get a mocked request"},
 			{"ALL", "/v1/{statusCode}", "Get a mocked request based on the http status code"},
 			{"GET", "/v1/raw/{id}", "Get a raw mocked request"},
+			{"PUT", "/v1/raw/{id}", "Update a mocked request"},
+			{"DELETE", "/v1/raw/{id}", "Delete a mocked request"},
 			{"GET", "/v1/list", "Get the list of all mocked requests"},
+			{"GET", "/v1/export", "Export all mocked requests in JSON or YAML"},
 			{"POST", "/v1/add", "Create a new mocked request"},
+			{"POST", "/v1/import/{format}", "Import mocked requests from curl, Mockapic, Postman or WireMock"},
+			{"GET", "/v1/journal", "Get the last requests received on the mocked requests"},
+			{"DELETE", "/v1/journal", "Clear the journal"},
+			{"GET", "/v1/predefined", "Get the status of the predefined requests files"},
+		})
+		t.AppendSeparator()
+		t.AppendRows([]table.Row{
+			{"POST", "/v1/sessions", "Open a session"},
+			{"GET", "/v1/sessions/{id}", "Get a session"},
+			{"DELETE", "/v1/sessions/{id}", "Close a session and remove its mocked requests"},
+			{"ALL", "/session/{id}/v1/...", "Call the /v1/... APIs on a session"},
+		})
+		t.AppendSeparator()
+		t.AppendRows([]table.Row{
+			{"GET", "/ns", "Get the list of all namespaces"},
+			{"GET", "/ns/{name}", "Get a namespace"},
+			{"PUT", "/ns/{name}", "Create a namespace"},
+			{"DELETE", "/ns/{name}", "Delete a namespace and its mocked requests"},
+			{"ALL", "/ns/{name}/v1/...", "Call the /v1/... APIs on a namespace"},
 		})
 
 		return t.Render()
@@ -222,75 +477,151 @@ func (s HTTPServer) home(w http.ResponseWriter, r *http.Request) {
 		s.version)))
 }
 
-func (s HTTPServer) getContentTypes(w http.ResponseWriter, r *http.Request) {
+func (s *HTTPServer) getContentTypes(w http.ResponseWriter, r *http.Request) {
 	s.writeResponse(w, r, pkg.CONTENT_TYPES, http.StatusOK)
 }
 
-func (s HTTPServer) getCharsets(w http.ResponseWriter, r *http.Request) {
+func (s *HTTPServer) getCharsets(w http.ResponseWriter, r *http.Request) {
 	s.writeResponse(w, r, pkg.CHARSET, http.StatusOK)
 }
 
-func (s HTTPServer) getStatusCodes(w http.ResponseWriter, r *http.Request) {
+func (s *HTTPServer) getStatusCodes(w http.ResponseWriter, r *http.Request) {
 	s.writeResponse(w, r, pkg.HTTP_CODES, http.StatusOK)
 }
 
-func (s HTTPServer) findMockedRequest(r *http.Request) (*internal.MockedRequest, int, error) {
-	var mockId string
-
+// findMockedRequest returns the mocked request called by the request {r} and the space where it is stored
+// ({nil} for the mocked requests based on the http status code).
+func (s *HTTPServer) findMockedRequest(r *http.Request, sc scope) (*internal.MockedRequest, *mockSpace, int, error) {
+	// a path can be bound to several mocked requests, the first one which matches the request is returned
+	// or the first one bound if none of them matches
+	var first *internal.MockedRequest
+	var firstSpace *mockSpace
 	decodedURI, _ := url.QueryUnescape(r.URL.Path)
-	if id, ok := s.PathToMockId[decodedURI]; ok {
-		mockId = id
-	} else {
-		url, err := url.ParseRequestURI(r.RequestURI)
-		if err != nil {
-			s.logger.Error(err, "error to parse URI", "uri", r.RequestURI)
-			return nil, 409, err
+	for _, space := range sc.spaces() {
+		ids, _ := space.routes.Get(decodedURI)
+		for _, id := range ids {
+			mock, err := space.mocker.Get(id)
+			if err != nil {
+				s.logger.Error(err, "error to get mock", "uri", r.RequestURI)
+				continue
+			}
+			if mock.Request.Match(r) {
+				return mock, space, -1, nil
+			}
+			if first == nil {
+				first, firstSpace = mock, space
+			}
 		}
-		mockId = path.Base(url.Path)
+	}
+	if first != nil {
+		return first, firstSpace, -1, nil
 	}
 
+	url, err := url.ParseRequestURI(r.RequestURI)
+	if err != nil {
+		s.logger.Error(err, "error to parse URI", "uri", r.RequestURI)
+		return nil, nil, 409, err
+	}
+	mockId := path.Base(url.Path)
+
 	// return a mocked request based on the http status code
 	if httpCode, err := strconv.Atoi(mockId); err == nil {
 		if value, ok := pkg.HTTP_CODES[httpCode]; ok {
 			mockedRequest := internal.NewMockedRequestFromHttpCode(httpCode, value)
-			return &mockedRequest, -1, nil
+			return &mockedRequest, nil, -1, nil
 		}
 	}
 
-	mock, err := s.mocker.Get(mockId)
-	if err != nil {
-		s.logger.Error(err, "error to get mock", "uri", r.RequestURI)
-		return nil, 404, err
+	for _, space := range sc.spaces() {
+		var mock *internal.MockedRequest
+		if mock, err = space.mocker.Get(mockId); err == nil {
+			return mock, space, -1, nil
+		}
 	}
 
-	return mock, -1, nil
+	s.logger.Error(err, "error to get mock", "uri", r.RequestURI)
+	return nil, nil, 404, err
 }
 
-func (s HTTPServer) getMockedRequest(w http.ResponseWriter, r *http.Request) {
-	mock, statusCode, err := s.findMockedRequest(r)
+func (s *HTTPServer) getMockedRequest(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	sc, err := s.scope(r)
 	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	mock, space, statusCode, err := s.findMockedRequest(r, sc)
+	if err == nil && mock.Expired(time.Now()) {
+		statusCode, err = http.StatusGone, fmt.Errorf("mock {%s} is expired", mock.Id)
+	} else if err == nil && !mock.Request.Match(r) {
+		statusCode, err = http.StatusNotFound, errors.New("request does not match the mocked request")
+	}
+
+	mockId, mockPath := "", ""
+	if err == nil {
+		mockId, mockPath, statusCode = mock.Id, metricsPath(mock), mock.Status
+		if failed := s.authenticate(w, r, sc, mock); failed != nil {
+			mock, statusCode = failed, failed.Status
+		} else if limited := s.rateLimit(w, r, sc, mock); limited != nil {
+			mock, statusCode = limited, limited.Status
+		} else if space != nil {
+			space.mocker.Touch(mock.Id)
+		}
+	}
+	sc.target().journal.Record(newJournalEntry(r, s.findRemoteAddr(r.RemoteAddr), mockId, statusCode))
+
+	if err != nil {
+		s.metrics.ObserveUnmatched()
 		writeError(w, err, statusCode)
 		return
 	}
 
-	NewResponse(w, "60s").Write(*mock, r.URL.Query().Get("delay"))
+	response := NewResponse(w, "60s").WithHome(s.bodyDirectory)
+	if delay := response.Delay(r.URL.Query().Get("delay")); delay > 0 {
+		s.metrics.ObserveDelay(delay)
+	}
+	statusCode = response.Write(*mock, r.URL.Query().Get("delay"))
+	s.metrics.ObserveRequest(mockId, mockPath, statusCode, time.Since(start))
 }
 
-func (s HTTPServer) getMockedRequestRaw(w http.ResponseWriter, r *http.Request) {
-	mock, statusCode, err := s.findMockedRequest(r)
+func (s *HTTPServer) mockedRequestRaw(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		s.updateMock(w, r)
+	case http.MethodDelete:
+		s.deleteMock(w, r)
+	default:
+		s.getMockedRequestRaw(w, r)
+	}
+}
+
+func (s *HTTPServer) getMockedRequestRaw(w http.ResponseWriter, r *http.Request) {
+	sc, err := s.scope(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	mock, _, statusCode, err := s.findMockedRequest(r, sc)
 	if err != nil {
 		writeError(w, err, statusCode)
 		return
 	}
 
+	if !s.owned(w, r, mock) {
+		return
+	}
+
 	if slicesutil.Exist(pkg.IS_DISPLAY_CONTENT, mock.ContentType) {
 		mock.Body = string(mock.Body64)
 	}
+	mock.TokenHash = ""
 
 	s.writeResponse(w, r, mock, http.StatusOK)
 }
 
-func (s HTTPServer) addNewMock(w http.ResponseWriter, r *http.Request) {
+func (s *HTTPServer) updateMock(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		s.logger.Error(err, "error to read body", "uri", r.RequestURI)
@@ -298,27 +629,254 @@ func (s HTTPServer) addNewMock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mock, err := s.mocker.New(r.URL.Query(), body)
+	space, mock, err := s.findOwnedMock(w, r)
 	if err != nil {
-		s.logger.Error(err, "error to create new mock", "uri", r.RequestURI, "body", body)
+		return
+	}
+
+	if !s.checkBodyFile(w, r) {
+		return
+	}
+
+	params := r.URL.Query()
+	params.Del("token")
+	updated, err := space.mocker.Update(mock.Id, params, body)
+	if err != nil {
+		s.logger.Error(err, "error to update mock", "uri", r.RequestURI, "body", body)
 		writeError(w, err, 500)
 		return
 	}
 
+	if mock.Path != "" && mock.Path != updated.Path {
+		space.routes.Delete("/v1"+mock.Path, mock.Id)
+	}
+	if updated.Path != "" {
+		space.routes.Set("/v1"+updated.Path, updated.Id)
+	}
+
+	s.writeResponse(w, r, map[string]interface{}{"id": updated.Id, "_links": s.getLinks(r, updated.MockedRequestLight)}, http.StatusOK)
+}
+
+func (s *HTTPServer) deleteMock(w http.ResponseWriter, r *http.Request) {
+	space, mock, err := s.findOwnedMock(w, r)
+	if err != nil {
+		return
+	}
+
+	if err := space.mocker.Delete(mock.Id); err != nil {
+		s.logger.Error(err, "error to delete mock", "uri", r.RequestURI)
+		writeError(w, err, 500)
+		return
+	}
 	if mock.Path != "" {
-		s.PathToMockId["/v1"+mock.Path] = mock.Id
+		space.routes.Delete("/v1"+mock.Path, mock.Id)
 	}
 
-	if s.totalNumberRequestsAllowed > 0 {
-		s.mocker.Clean(s.totalNumberRequestsAllowed)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findOwnedMock finds the mocked request {/v1/raw/{id}} of the request {r} and its space,
+// and writes the error response if it does not exist or if the owner token is not valid.
+func (s *HTTPServer) findOwnedMock(w http.ResponseWriter, r *http.Request) (*mockSpace, *internal.MockedRequest, error) {
+	sc, err := s.scope(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return nil, nil, err
 	}
 
+	mockId := path.Base(r.URL.Path)
+	for _, space := range sc.spaces() {
+		if mock, err := space.mocker.Get(mockId); err == nil {
+			if !s.owned(w, r, mock) {
+				return nil, nil, fmt.Errorf("mock {%s} is not owned", mockId)
+			}
+			return space, mock, nil
+		}
+	}
+
+	err = fmt.Errorf("mock {%s} does not exist", mockId)
+	writeError(w, err, http.StatusNotFound)
+	return nil, nil, err
+}
+
+// owned checks the owner tokens of the {mock} sent in the {X-Mockapic-Token} headers or in the {token} parameters
+// and writes a 401 or a 403 response if none is valid, the authenticated requests are allowed on all the mocked requests.
+func (s *HTTPServer) owned(w http.ResponseWriter, r *http.Request, mock *internal.MockedRequest) bool {
+	tokens := requestTokens(r)
+	if s.ownedByAny(mock, tokens) {
+		return true
+	}
+
+	if len(tokens) == 0 {
+		writeError(w, fmt.Errorf("token of mock {%s} is required", mock.Id), http.StatusUnauthorized)
+	} else {
+		writeError(w, fmt.Errorf("token of mock {%s} is not valid", mock.Id), http.StatusForbidden)
+	}
+	return false
+}
+
+// ownedByAny returns true if the management endpoints are authenticated or if one of the {tokens} owns the {mock}.
+func (s *HTTPServer) ownedByAny(mock *internal.MockedRequest, tokens []string) bool {
+	if s.auth != nil || mock.TokenHash == "" {
+		return true
+	}
+	return slices.ContainsFunc(tokens, mock.Owned)
+}
+
+// requestTokens returns the owner tokens of the request {r} ({X-Mockapic-Token} headers and {token} parameters separated by a comma).
+func requestTokens(r *http.Request) []string {
+	var tokens []string
+	for _, value := range append(r.Header.Values("X-Mockapic-Token"), r.URL.Query()["token"]...) {
+		tokens = append(tokens, slicesutil.FilterByNonEmpty(strings.Split(value, ","))...)
+	}
+	return tokens
+}
+
+// checkBodyFile checks the {bodyFile} parameter of the request {r} and writes a 400 response if the file does not exist.
+func (s *HTTPServer) checkBodyFile(w http.ResponseWriter, r *http.Request) bool {
+	if bodyFile := r.URL.Query().Get("bodyFile"); bodyFile != "" {
+		if _, err := internal.BodyFilePath(s.bodyDirectory, bodyFile); err != nil {
+			writeError(w, err, http.StatusBadRequest)
+			return false
+		}
+	}
+	return true
+}
+
+func (s *HTTPServer) addNewMock(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.logger.Error(err, "error to read body", "uri", r.RequestURI)
+		writeError(w, err, 500)
+		return
+	}
+
+	sc, err := s.openScope(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	target := sc.target()
+
+	if !s.allowQuota(w, r, sc, 1) {
+		return
+	}
+
+	if !s.checkBodyFile(w, r) {
+		return
+	}
+
+	mock, err := target.mocker.New(r.URL.Query(), body)
+	if err != nil {
+		s.logger.Error(err, "error to create new mock", "uri", r.RequestURI, "body", body)
+		writeError(w, err, 500)
+		return
+	}
+	s.recordQuota(r, sc, mock.Id)
+
+	if mock.Path != "" {
+		target.routes.Set("/v1"+mock.Path, mock.Id)
+	}
+
+	s.clean(sc)
+
 	s.countRemoteAddr(r.RemoteAddr)
+	s.metrics.ObserveCreated(s.findRemoteAddr(r.RemoteAddr))
 
-	s.writeResponse(w, r, map[string]interface{}{"id": mock.Id, "_links": s.getLinks(r, mock.MockedRequestLight)}, http.StatusCreated)
+	s.writeResponse(w, r, s.toCreated(r, mock), http.StatusCreated)
 }
 
-func (s HTTPServer) countRemoteAddr(requestRemoteAddr string) {
+func (s *HTTPServer) importMocks(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.logger.Error(err, "error to read body", "uri", r.RequestURI)
+		writeError(w, err, 500)
+		return
+	}
+
+	result, err := importer.Import(path.Base(r.URL.Path), body)
+	if err != nil {
+		s.logger.Error(err, "error to import mocks", "uri", r.RequestURI)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	sc, err := s.openScope(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	target := sc.target()
+
+	if !s.allowQuota(w, r, sc, len(result.Mocks)) {
+		return
+	}
+
+	created := []map[string]interface{}{}
+	for _, mockedRequest := range result.Mocks {
+		if mockedRequest.BodyFile != "" {
+			if _, err := internal.BodyFilePath(s.bodyDirectory, mockedRequest.BodyFile); err != nil {
+				result.Unsupported = append(result.Unsupported, fmt.Sprintf("path[%s]: %s", mockedRequest.Path, err.Error()))
+				continue
+			}
+		}
+		mock, err := target.mocker.Add(mockedRequest)
+		if err != nil {
+			s.logger.Error(err, "error to create imported mock", "uri", r.RequestURI, "path", mockedRequest.Path)
+			result.Unsupported = append(result.Unsupported, fmt.Sprintf("path[%s]: %s", mockedRequest.Path, err.Error()))
+			continue
+		}
+		if mock.Path != "" {
+			target.routes.Set("/v1"+mock.Path, mock.Id)
+		}
+		s.recordQuota(r, sc, mock.Id)
+		s.countRemoteAddr(r.RemoteAddr)
+		s.metrics.ObserveCreated(s.findRemoteAddr(r.RemoteAddr))
+		created = append(created, s.toCreated(r, mock))
+	}
+
+	s.clean(sc)
+
+	unsupported := result.Unsupported
+	if unsupported == nil {
+		unsupported = []string{}
+	}
+
+	s.writeResponse(w, r, map[string]interface{}{"mocks": created, "unsupported": unsupported}, http.StatusCreated)
+}
+
+// clean removes the oldest mocked requests of the scope {sc} over the max limit of its namespace and their paths.
+func (s *HTTPServer) clean(sc scope) {
+	if sc.ns.totalNumberRequestsAllowed <= 0 {
+		return
+	}
+	target := sc.target()
+	mocks, err := target.mocker.Clean(sc.ns.totalNumberRequestsAllowed)
+	if err != nil {
+		s.logger.Error(err, "error to clean mocks", "namespace", sc.ns.Name)
+	}
+
+	for _, mock := range mocks {
+		if mock.Path != "" {
+			target.routes.Delete("/v1"+mock.Path, mock.Id)
+		}
+	}
+	s.metrics.ObserveEvictions(len(mocks))
+}
+
+// toCreated returns the identifier, the links and the owner token of the created {mock}.
+func (s *HTTPServer) toCreated(r *http.Request, mock *internal.MockedRequest) map[string]interface{} {
+	created := map[string]interface{}{"id": mock.Id, "_links": s.getLinks(r, mock.MockedRequestLight)}
+	if mock.Token != "" {
+		created["token"] = mock.Token
+	}
+	return created
+}
+
+func (s *HTTPServer) countRemoteAddr(requestRemoteAddr string) {
+	s.remoteAddrMu.Lock()
+	defer s.remoteAddrMu.Unlock()
+
 	remoteAddrHistory := s.getRemoteAddr()
 
 	remoteAddr := s.findRemoteAddr(requestRemoteAddr)
@@ -334,7 +892,7 @@ func (s HTTPServer) countRemoteAddr(requestRemoteAddr string) {
 	}
 }
 
-func (s HTTPServer) getProtocol(r *http.Request) string {
+func (s *HTTPServer) getProtocol(r *http.Request) string {
 	protocol := "https"
 	if r.TLS == nil {
 		protocol = "http"
@@ -342,7 +900,7 @@ func (s HTTPServer) getProtocol(r *http.Request) string {
 	return protocol
 }
 
-func (s HTTPServer) findRemoteAddr(data string) string {
+func (s *HTTPServer) findRemoteAddr(data string) string {
 	ipPort := stringsutil.Split(data, ":", "")
 	if len(ipPort) == 0 {
 		return "[::1]"
@@ -353,7 +911,7 @@ func (s HTTPServer) findRemoteAddr(data string) string {
 	return data[:len(data)-(len(ipPort[len(ipPort)-1])+1)]
 }
 
-func (s HTTPServer) getRemoteAddr() map[string]int {
+func (s *HTTPServer) getRemoteAddr() map[string]int {
 	loaded, err := iosutil.Load(s.workingDirectory + "/remote-addr.json")
 	if err != nil {
 		s.logger.Error(err, "error to load remote addresses", "file", s.workingDirectory+"/remote-addr.json")
@@ -369,27 +927,45 @@ func (s HTTPServer) getRemoteAddr() map[string]int {
 	return data
 }
 
-func (s HTTPServer) getLinks(r *http.Request, mock internal.MockedRequestLight) map[string]string {
+func (s *HTTPServer) getLinks(r *http.Request, mock internal.MockedRequestLight) map[string]string {
+	baseURL := s.getProtocol(r) + "://" + r.Host + s.prefix(r)
+
 	values := map[string]string{
-		"self": s.getProtocol(r) + "://" + r.Host + "/v1/" + mock.Id,
-		"raw":  s.getProtocol(r) + "://" + r.Host + "/v1/raw/" + mock.Id,
+		"self": baseURL + "/v1/" + mock.Id,
+		"raw":  baseURL + "/v1/raw/" + mock.Id,
 	}
 
 	if len(mock.Path) > 0 {
-		values["path"] = s.getProtocol(r) + "://" + r.Host + "/v1" + mock.Path
+		values["path"] = baseURL + "/v1" + mock.Path
 	}
 
 	return values
 }
 
-func (s HTTPServer) list(w http.ResponseWriter, r *http.Request) {
-	mockedRequestLights, err := s.mocker.List()
+func (s *HTTPServer) list(w http.ResponseWriter, r *http.Request) {
+	sc, err := s.scope(r)
 	if err != nil {
-		s.logger.Error(err, "error to get mocked list", "uri", r.RequestURI)
-		writeError(w, err, 500)
+		writeError(w, err, http.StatusBadRequest)
 		return
 	}
 
+	// without authentication, only the mocked requests owned by the tokens of the request are listed
+	tokens := requestTokens(r)
+	mockedRequestLights := []internal.MockedRequestLight{}
+	for _, space := range sc.spaces() {
+		values, err := space.mocker.List()
+		if err != nil {
+			s.logger.Error(err, "error to get mocked list", "uri", r.RequestURI)
+			writeError(w, err, 500)
+			return
+		}
+		for _, mrl := range values {
+			if mock, err := space.mocker.Get(mrl.Id); err == nil && s.ownedByAny(mock, tokens) {
+				mockedRequestLights = append(mockedRequestLights, mrl)
+			}
+		}
+	}
+
 	all := slicesutil.TransformT[internal.MockedRequestLight, MockedRequestLightWithLinks](mockedRequestLights, func(mrl internal.MockedRequestLight) (*MockedRequestLightWithLinks, error) {
 		return &MockedRequestLightWithLinks{
 			MockedRequestLight: mrl,
@@ -404,7 +980,54 @@ func (s HTTPServer) list(w http.ResponseWriter, r *http.Request) {
 	s.writeResponse(w, r, all, http.StatusOK)
 }
 
-func (s HTTPServer) writeResponse(w http.ResponseWriter, r *http.Request, data any, statusCode int) {
+// export writes all the mocked requests of the scope in the {format} (json or yaml) which can be
+// used as a predefined file or imported with {/v1/import/mockapic}.
+func (s *HTTPServer) export(w http.ResponseWriter, r *http.Request) {
+	format := stringsutil.OrElse(r.URL.Query().Get("format"), internal.FORMAT_JSON)
+	if !slicesutil.Exist(internal.FORMATS, format) {
+		writeError(w, fmt.Errorf("format {%s} does not exist", format), http.StatusBadRequest)
+		return
+	}
+
+	sc, err := s.scope(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	// without authentication, only the mocked requests owned by the tokens of the request are exported
+	tokens := requestTokens(r)
+	values := []internal.PredefinedMockedRequest{}
+	for _, space := range sc.spaces() {
+		mockedRequestLights, err := space.mocker.List()
+		if err != nil {
+			s.logger.Error(err, "error to get mocked list", "uri", r.RequestURI)
+			writeError(w, err, 500)
+			return
+		}
+		for _, mrl := range mockedRequestLights {
+			mock, err := space.mocker.Get(mrl.Id)
+			if err != nil || !s.ownedByAny(mock, tokens) {
+				continue
+			}
+			mock.TokenHash = ""
+			values = append(values, internal.PredefinedMockedRequest{MockedRequest: *mock})
+		}
+	}
+
+	data, err := internal.MarshalPredefined(values, format)
+	if err != nil {
+		s.logger.Error(err, "error to export mocks", "uri", r.RequestURI, "format", format)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/"+format)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func (s *HTTPServer) writeResponse(w http.ResponseWriter, r *http.Request, data any, statusCode int) {
 	bytes, err := jsonsutil.Marshal(data)
 	if err != nil {
 		s.logger.Error(err, "error to marshal data", "uri", r.RequestURI, "data", data)
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joakim-ribier/mockapic/internal"
+	"golang.org/x/net/http2"
+)
+
+// newHTTP2TestServer creates a server with the mocked requests matching the protocol version,
+// and with trailers and push hints.
+func newHTTP2TestServer(t *testing.T) *HTTPServer {
+	mock := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, mock, *logger, "test")
+
+	for path, params := range map[string]map[string][]string{
+		"/h2": {"protocol": {"HTTP/2"}, "trailer:Grpc-Status": {"0"}, "trailer:Grpc-Message": {"OK"}, "push": {"/v1/style.css,/v1/app.js"}},
+		"/h1": {"protocol": {"1.1"}},
+	} {
+		params["status"], params["contentType"], params["charset"], params["path"] = []string{"200"}, []string{"text/plain"}, []string{"UTF-8"}, []string{path}
+		created, err := mock.New(params, []byte(path))
+		if err != nil {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+		}
+		s.Routes.Set("/v1"+path, created.Id)
+	}
+	return s
+}
+
+// assertHTTP2 calls the mocked requests with the {client} speaking HTTP/2,
+// checking for a valid return value.
+func assertHTTP2(t *testing.T, s *HTTPServer, client *http.Client, url string) {
+	resp, err := client.Get(url + "/v1/h2")
+	if err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.ProtoMajor != 2 || resp.StatusCode != http.StatusOK || string(body) != "/h2" {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, resp.Proto, resp.StatusCode, "HTTP/2.0 200")
+	}
+	if resp.Trailer.Get("Grpc-Status") != "0" || resp.Trailer.Get("Grpc-Message") != "OK" {
+		t.Fatalf(`result: {%v} but expected {%v}`, resp.Trailer, "Grpc-Status and Grpc-Message")
+	}
+	if links := resp.Header.Values("Link"); len(links) != 2 || links[0] != "</v1/style.css>; rel=preload" {
+		t.Fatalf(`result: {%v} but expected {%v}`, links, "</v1/style.css>; rel=preload")
+	}
+
+	resp, err = client.Get(url + "/v1/h1")
+	if err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, resp, err, http.StatusNotFound)
+	}
+	resp.Body.Close()
+
+	if entry := s.journal.Entries()[0]; entry.Protocol != "HTTP/2.0" {
+		t.Fatalf(`result: {%v} but expected {%v}`, entry.Protocol, "HTTP/2.0")
+	}
+}
+
+// TestHTTP2 calls the mocked requests on a TLS server with a HTTP/2 client,
+// checking for a valid return value.
+func TestHTTP2(t *testing.T) {
+	s := newHTTP2TestServer(t)
+
+	server := httptest.NewUnstartedServer(s.handler())
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	assertHTTP2(t, s, server.Client(), server.URL)
+}
+
+// TestH2C calls the mocked requests on a plain server with a h2c (HTTP/2 cleartext) client,
+// checking for a valid return value.
+func TestH2C(t *testing.T) {
+	h2c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+
+	s := newHTTP2TestServer(t)
+	server := httptest.NewServer(s.newServer(Listener{Port: "{port}"}, s.handler(), nil).Handler)
+	defer server.Close()
+
+	assertHTTP2(t, s, h2c, server.URL)
+
+	// the HTTP/1.1 clients are still served
+	resp, err := http.Get(server.URL + "/v1/h1")
+	if err != nil || resp.StatusCode != http.StatusOK || resp.ProtoMajor != 1 {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, resp, err, http.StatusOK)
+	}
+	resp.Body.Close()
+
+	// h2c is disabled
+	s.WithHTTP2(false)
+	disabled := httptest.NewServer(s.newServer(Listener{Port: "{port}"}, s.handler(), nil).Handler)
+	defer disabled.Close()
+	if _, err := h2c.Get(disabled.URL + "/v1/h2"); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/joakim-ribier/go-utils/pkg/httpsutil"
 	"github.com/joakim-ribier/go-utils/pkg/iosutil"
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
 	"github.com/joakim-ribier/go-utils/pkg/logsutil"
 	"github.com/joakim-ribier/go-utils/pkg/stringsutil"
 	"github.com/joakim-ribier/mockapic/internal"
@@ -30,9 +31,16 @@ func (m *MockerTest) Get(mockId string) (*internal.MockedRequest, error) {
 	if m.mockResponse != nil && m.mockResponse.Id == mockId {
 		return m.mockResponse, nil
 	}
+	for _, mockResponseLight := range m.mockResponseLights {
+		if mockResponseLight.Id == mockId {
+			return &internal.MockedRequest{MockedRequestLight: mockResponseLight}, nil
+		}
+	}
 	return nil, errors.New("mockId does not exist")
 }
 
+func (m *MockerTest) Touch(mockId string) {}
+
 func (m *MockerTest) List() ([]internal.MockedRequestLight, error) {
 	if m.mockResponseLights != nil {
 		return m.mockResponseLights, nil
@@ -67,9 +75,37 @@ func (m *MockerTest) New(reqParams map[string][]string, body []byte) (*internal.
 	return mockedRequest, nil
 }
 
-func (m *MockerTest) Clean(maxLimit int) (int, error) {
+func (m *MockerTest) Add(mock internal.MockedRequest) (*internal.MockedRequest, error) {
+	if mock.Status == 0 {
+		return nil, errors.New("error to add mocked response")
+	}
+	mock.Id = "{id-" + mock.Path + "}"
+	m.mockResponse = &mock
+	return &mock, nil
+}
+
+func (m *MockerTest) Update(mockId string, reqParams map[string][]string, body []byte) (*internal.MockedRequest, error) {
+	if m.mockResponse == nil || m.mockResponse.Id != mockId {
+		return nil, errors.New("mockId does not exist")
+	}
+	return m.New(reqParams, body)
+}
+
+func (m *MockerTest) Delete(mockId string) error {
+	if m.mockResponse == nil || m.mockResponse.Id != mockId {
+		return errors.New("mockId does not exist")
+	}
+	m.mockResponse = nil
+	return nil
+}
+
+func (m *MockerTest) Clean(maxLimit int) ([]internal.MockedRequestLight, error) {
 	m.clean = true
-	return 0, nil
+	return nil, nil
+}
+
+func (m *MockerTest) Purge(now time.Time) ([]internal.MockedRequestLight, error) {
+	return nil, nil
 }
 
 var workingDirectory string
@@ -312,7 +348,7 @@ func TestGetMockedRequestEndpointWithPath(t *testing.T) {
 	}
 
 	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, mocker, *logger, "test")
-	s.PathToMockId["/my-path"] = "{id}"
+	s.Routes.Set("/my-path", "{id}")
 
 	s.getMockedRequest(w, req)
 
@@ -322,6 +358,38 @@ func TestGetMockedRequestEndpointWithPath(t *testing.T) {
 	}
 }
 
+// TestGetMockedRequestEndpointWithRequestMatcher calls HTTPServer.getMockedRequest(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestGetMockedRequestEndpointWithRequestMatcher(t *testing.T) {
+	mocker := &MockerTest{
+		mockResponse: &internal.MockedRequest{
+			MockedRequestLight: internal.MockedRequestLight{
+				Id: "{id}",
+				MockedRequestHeader: internal.MockedRequestHeader{
+					Status:      200,
+					ContentType: "text/plain",
+					Charset:     "UTF-8",
+					Request:     &internal.MockedRequestMatcher{Method: "POST", Query: map[string]string{"base": "EUR"}},
+				},
+			},
+			Body: "Hello World",
+		},
+	}
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, mocker, *logger, "test")
+
+	w := httptest.NewRecorder()
+	s.getMockedRequest(w, httptest.NewRequest(http.MethodPost, "http://localhost:3333/v1/{id}?base=EUR", nil))
+	if res, _ := geResultResponse(w, t); res.Status != "200 OK" {
+		t.Fatalf(`result: {%v} but expected {%v}`, res, "200")
+	}
+
+	w = httptest.NewRecorder()
+	s.getMockedRequest(w, httptest.NewRequest(http.MethodGet, "http://localhost:3333/v1/{id}?base=EUR", nil))
+	if res, _ := geResultResponse(w, t); res.Status != "404 Not Found" {
+		t.Fatalf(`result: {%v} but expected {%v}`, res, "404")
+	}
+}
+
 func TestGetMockedRequestEndpointWithStatusCode(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "http://localhost:3333/v1/418", nil)
 	w := httptest.NewRecorder()
@@ -499,12 +567,13 @@ func TestAddNewEndpoint(t *testing.T) {
 		Body64: []byte("Hello World"),
 	}
 
+	mockIds, _ := s.Routes.Get("/v1/my-path")
 	if res.Status != "201 Created" ||
 		string(body) != `{"_links":{"path":"http://localhost:3333/v1/my-path","raw":"http://localhost:3333/v1/raw/{id}","self":"http://localhost:3333/v1/{id}"},"id":"{id}"}` ||
 		!mocker.mockResponse.Equals(expected) ||
 		!mocker.clean ||
 		len(s.getRemoteAddr()) != 1 ||
-		s.PathToMockId["/v1/my-path"] != "{id}" {
+		strings.Join(mockIds, ",") != "{id}" {
 		t.Fatalf(`result: {%v} but expected {%v}`, res, expected)
 	}
 }
@@ -526,6 +595,173 @@ func TestAddNewEndpointWithBadRequest(t *testing.T) {
 	}
 }
 
+// TestAddNewEndpointWithBodyFile calls HTTPServer.addNewMock(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestAddNewEndpointWithBodyFile(t *testing.T) {
+	directory := t.TempDir()
+	os.MkdirAll(filepath.Join(directory, "files"), os.ModePerm)
+	os.WriteFile(filepath.Join(directory, "files", "user.json"), []byte(`{"id":1}`), 0644)
+	os.WriteFile(filepath.Join(directory, "mockapic-ca.key"), []byte("secret"), 0600)
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), directory, -1, internal.NewMock(t.TempDir(), nil, *logger), *logger, "test").handler()
+
+	if body := serve(t, handler, http.MethodPost, "/v1/new?status=200&path=/user&bodyFile=user.yaml", "", nil, http.StatusBadRequest).Body.String(); body != `{"message": "bodyFile {user.yaml} does not exist"}` {
+		t.Fatalf(`result: {%v} but expected {%v}`, body, "bodyFile {user.yaml} does not exist")
+	}
+
+	// only the files of the body files directory can be served
+	for _, bodyFile := range []string{"mockapic-ca.key", "../mockapic-ca.key"} {
+		serve(t, handler, http.MethodPost, "/v1/new?status=200&path=/key&bodyFile="+bodyFile, "", nil, http.StatusBadRequest)
+	}
+
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&path=/user&bodyFile=user.json", "", nil, http.StatusCreated)
+
+	if w := serve(t, handler, http.MethodGet, "/v1/user", "", nil, http.StatusOK); w.Body.String() != `{"id":1}` || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Body.String(), `{"id":1}`)
+	}
+}
+
+// TestOwnerToken calls HTTPServer.mockedRequestRaw(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestOwnerToken(t *testing.T) {
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMock(t.TempDir(), nil, *logger), *logger, "test").handler()
+
+	created, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hello", "Hello World", nil, http.StatusCreated).Body.Bytes())
+	mockId, token := created["id"].(string), created["token"].(string)
+	if token == "" {
+		t.Fatalf(`result: {%v} but expected {%v}`, created, "token")
+	}
+
+	serve(t, handler, http.MethodGet, "/v1/raw/"+mockId, "", nil, http.StatusUnauthorized)
+	serve(t, handler, http.MethodGet, "/v1/raw/"+mockId, "", http.Header{"X-Mockapic-Token": {"bad-token"}}, http.StatusForbidden)
+	if r := serve(t, handler, http.MethodGet, "/v1/raw/"+mockId+"?token="+token, "", nil, http.StatusOK).Body.String(); !strings.Contains(r, `"body":"Hello World"`) || strings.Contains(r, "token") {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello World")
+	}
+
+	serve(t, handler, http.MethodPut, "/v1/raw/"+mockId+"?status=201&contentType=text/plain&charset=UTF-8&path=/updated", "Hello World", http.Header{"X-Mockapic-Token": {"bad-token"}}, http.StatusForbidden)
+	serve(t, handler, http.MethodPut, "/v1/raw/"+mockId+"?status=201&contentType=text/plain&charset=UTF-8&path=/updated", "Hello World", http.Header{"X-Mockapic-Token": {token}}, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/v1/hello", "", nil, http.StatusNotFound)
+	serve(t, handler, http.MethodGet, "/v1/updated", "", nil, http.StatusCreated)
+
+	serve(t, handler, http.MethodDelete, "/v1/raw/"+mockId, "", nil, http.StatusUnauthorized)
+	serve(t, handler, http.MethodDelete, "/v1/raw/"+mockId, "", http.Header{"X-Mockapic-Token": {token}}, http.StatusNoContent)
+	serve(t, handler, http.MethodDelete, "/v1/raw/"+mockId, "", http.Header{"X-Mockapic-Token": {token}}, http.StatusNotFound)
+	serve(t, handler, http.MethodGet, "/v1/updated", "", nil, http.StatusNotFound)
+}
+
+// ##
+// #### ~/v1/import/{format} endpoint
+// ##
+
+// TestImportMocksEndpoint calls HTTPServer.importMocks(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestImportMocksEndpoint(t *testing.T) {
+	data := `{"mappings": [
+		{"request": {"urlPath": "/hello"}, "response": {"status": 200, "body": "Hello World"}},
+		{"request": {"method": "GET", "urlPath": "/bad-status"}, "response": {"status": 999}}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:3333/v1/import/wiremock", strings.NewReader(data))
+	w := httptest.NewRecorder()
+
+	mocker := &MockerTest{}
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, 100, mocker, *logger, "test")
+
+	s.importMocks(w, req)
+
+	res, body := geResultResponse(w, t)
+
+	expected := `{"mocks":[{"_links":{"path":"http://localhost:3333/v1/hello","raw":"http://localhost:3333/v1/raw/{id-/hello}","self":"http://localhost:3333/v1/{id-/hello}"},"id":"{id-/hello}"}],"unsupported":["mapping[#1]: status {999} is not supported, mapping ignored"]}`
+	mockIds, _ := s.Routes.Get("/v1/hello")
+	if res.Status != "201 Created" ||
+		string(body) != expected ||
+		!mocker.clean ||
+		strings.Join(mockIds, ",") != "{id-/hello}" {
+		t.Fatalf(`result: {%v} but expected {%v}`, string(body), expected)
+	}
+}
+
+// TestImportMocksEndpointWithHeadAndOptions calls HTTPServer.importMocks(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestImportMocksEndpointWithHeadAndOptions(t *testing.T) {
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test").handler()
+
+	if r := serve(t, handler, http.MethodPost, "/v1/import/curl", `{"command": "curl -I https://api.com/ping", "response": "HTTP/1.1 204 No Content\n\n"}`, nil, http.StatusCreated).Body.String(); !strings.Contains(r, `"unsupported":[]`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, `"unsupported":[]`)
+	}
+	if r := serve(t, handler, http.MethodPost, "/v1/import/wiremock", `{"mappings": [{"request": {"method": "OPTIONS", "urlPath": "/ping"}, "response": {"status": 200}}]}`, nil, http.StatusCreated).Body.String(); !strings.Contains(r, `"unsupported":[]`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, `"unsupported":[]`)
+	}
+
+	serve(t, handler, http.MethodHead, "/v1/ping", "", nil, http.StatusNoContent)
+	serve(t, handler, http.MethodOptions, "/v1/ping", "", nil, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/v1/ping", "", nil, http.StatusNotFound)
+}
+
+// TestImportMocksEndpointWithBadFormat calls HTTPServer.importMocks(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestImportMocksEndpointWithBadFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:3333/v1/import/wrong-format", strings.NewReader("{}"))
+	w := httptest.NewRecorder()
+
+	NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, &MockerTest{}, *logger, "test").importMocks(w, req)
+
+	res, body := geResultResponse(w, t)
+	if res.Status != "400 Bad Request" || string(body) != `{"message": "format {wrong-format} does not exist"}` {
+		t.Fatalf(`result: {%v} but expected {%v}`, string(body), "400")
+	}
+}
+
+// ##
+// #### ~/v1/export endpoint
+// ##
+
+// TestExportEndpoint calls HTTPServer.export(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestExportEndpoint(t *testing.T) {
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMock(t.TempDir(), nil, *logger), *logger, "test")
+	handler := s.handler()
+
+	created, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hello", "Hello\nWorld\n", nil, http.StatusCreated).Body.Bytes())
+	other, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/other", "Other", nil, http.StatusCreated).Body.Bytes())
+
+	// only the mocked requests owned by the tokens are exported
+	if data := serve(t, handler, http.MethodGet, "/v1/export?format=yaml", "", nil, http.StatusOK).Body.String(); strings.Contains(data, "path:") {
+		t.Fatalf(`result: {%v} but expected {%v}`, data, "[]")
+	}
+	if data := serve(t, handler, http.MethodGet, "/v1/export?format=yaml&token="+created["token"].(string)+","+other["token"].(string), "", nil, http.StatusOK).Body.String(); !strings.Contains(data, "path: /hello") || !strings.Contains(data, "path: /other") {
+		t.Fatalf(`result: {%v} but expected {%v}`, data, "/hello and /other")
+	}
+
+	data := serve(t, handler, http.MethodGet, "/v1/export?format=yaml&token="+created["token"].(string), "", nil, http.StatusOK).Body.String()
+	if strings.Contains(data, "/other") {
+		t.Fatalf(`result: {%v} but expected {%v}`, data, "/hello")
+	}
+	if !strings.Contains(data, "  body: |\n    Hello\n    World\n") || !strings.Contains(data, "  path: /hello\n") {
+		t.Fatalf(`result: {%v} but expected {%v}`, data, "body: |")
+	}
+	serve(t, handler, http.MethodGet, "/v1/export?format=xml", "", nil, http.StatusBadRequest)
+
+	// the list contains only the mocked requests owned by the tokens, as the export
+	if r := serve(t, handler, http.MethodGet, "/v1/list", "", nil, http.StatusOK).Body.String(); r != "[]" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "[]")
+	}
+	if r := serve(t, handler, http.MethodGet, "/v1/list?token="+created["token"].(string), "", nil, http.StatusOK).Body.String(); !strings.Contains(r, created["id"].(string)) || strings.Contains(r, other["id"].(string)) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, created["id"])
+	}
+
+	// the raw endpoint accepts the tokens separated by a comma
+	serve(t, handler, http.MethodGet, "/v1/raw/"+created["id"].(string)+"?token="+other["token"].(string)+","+created["token"].(string), "", nil, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/v1/raw/"+created["id"].(string)+"?token="+other["token"].(string), "", nil, http.StatusForbidden)
+
+	// the export is imported in a new server
+	imported := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMock(t.TempDir(), nil, *logger), *logger, "test")
+	if r := serve(t, imported.handler(), http.MethodPost, "/v1/import/mockapic", data, nil, http.StatusCreated).Body.String(); !strings.Contains(r, `"unsupported":[]`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, http.StatusCreated)
+	}
+	if r := serve(t, imported.handler(), http.MethodGet, "/v1/hello", "", nil, http.StatusOK).Body.String(); r != "Hello\nWorld\n" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello\nWorld\n")
+	}
+}
+
 // TestFindRemoteAddr calls HTTPServer.findRemoteAddr(string),
 // checking for a valid return value.
 func TestFindRemoteAddr(t *testing.T) {
@@ -606,3 +842,31 @@ func geResultResponse(w *httptest.ResponseRecorder, t *testing.T) (http.Response
 
 	return *res, data
 }
+
+// serve serves the request {method} {url} with the {body} and the {header} on the {handler}
+// and fails the test if the response status code is not {expectedStatusCode},
+// the {url} without scheme and host is relative to {http://localhost:3333}.
+func serve(t *testing.T, handler http.Handler, method, url, body string, header http.Header, expectedStatusCode int) *httptest.ResponseRecorder {
+	t.Helper()
+	if strings.HasPrefix(url, "/") {
+		url = "http://localhost:3333" + url
+	}
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return serveRequest(t, handler, req, expectedStatusCode)
+}
+
+// serveRequest serves the request {req} on the {handler} and fails the test if the response status code is not {expectedStatusCode}.
+func serveRequest(t *testing.T, handler http.Handler, req *http.Request, expectedStatusCode int) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != expectedStatusCode {
+		t.Fatalf(`result: [%s] %s => {%v} but expected {%v}`, req.Method, req.URL, w.Code, expectedStatusCode)
+	}
+	return w
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"time"
+)
+
+var JANITOR_INTERVAL = time.Minute
+
+// janitor removes the expired resources every {interval}
+func (s *HTTPServer) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		s.expire(now)
+	}
+}
+
+// expire closes the sessions and removes the mocked requests expired at {now} of all the namespaces,
+// the refilled rate limit buckets, the expired OAuth2 tokens and the clients without quota to keep.
+func (s *HTTPServer) expire(now time.Time) int {
+	namespaces := []*Namespace{s.defaultNamespace()}
+	if s.namespaces != nil {
+		namespaces = append(namespaces, s.namespaces.List()...)
+	}
+
+	nbSessions, nbMocks := 0, 0
+	spaces := map[string]*mockSpace{}
+	for _, ns := range namespaces {
+		nbSessions = nbSessions + ns.sessions.Expire(now)
+		nbMocks = nbMocks + s.purge(&ns.mockSpace, now)
+		spaces[scope{ns: ns}.key()] = &ns.mockSpace
+		for _, session := range ns.sessions.List() {
+			nbMocks = nbMocks + s.purge(&session.mockSpace, now)
+			spaces[scope{ns: ns, session: session}.key()] = &session.mockSpace
+		}
+	}
+
+	s.rateLimiter.Expire(now)
+	s.oauth.Expire(now)
+	if s.quotas != nil {
+		s.quotas.Expire(now, func(key string) []string {
+			if space, ok := spaces[key]; ok {
+				return storedIds(space)
+			}
+			return nil
+		})
+	}
+
+	if nbSessions > 0 {
+		s.logger.Info("sessions expired", "nb", nbSessions)
+	}
+	if nbMocks > 0 {
+		s.logger.Info("mocked requests expired", "nb", nbMocks)
+	}
+	return nbSessions + nbMocks
+}
+
+// purge removes the mocked requests of the {space} expired at {now} and their paths.
+func (s *HTTPServer) purge(space *mockSpace, now time.Time) int {
+	mocks, err := space.mocker.Purge(now)
+	if err != nil {
+		s.logger.Error(err, "error to purge mocked requests")
+		return 0
+	}
+
+	for _, mock := range mocks {
+		if mock.Path != "" {
+			space.routes.Delete("/v1"+mock.Path, mock.Id)
+		}
+	}
+	return len(mocks)
+}
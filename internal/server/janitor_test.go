@@ -0,0 +1,34 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestExpiredMockedRequest calls HTTPServer.getMockedRequest(http.ResponseWriter, *http.Request) and HTTPServer.expire(time.Time),
+// checking for a valid return value.
+func TestExpiredMockedRequest(t *testing.T) {
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test")
+	handler := s.handler()
+
+	expiresAt := url.QueryEscape(time.Now().Add(-time.Second).Format(time.RFC3339))
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/expired&expiresAt="+expiresAt, "", nil, http.StatusCreated)
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/alive&ttl=1h", "", nil, http.StatusCreated)
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/alive&ttl=bad", "", nil, http.StatusInternalServerError)
+
+	serve(t, handler, http.MethodGet, "/v1/expired", "", nil, http.StatusGone)
+	serve(t, handler, http.MethodGet, "/v1/alive", "", nil, http.StatusOK)
+
+	if nb := s.expire(time.Now()); nb != 1 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 1)
+	}
+	if _, ok := s.Routes.Get("/v1/expired"); ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, false)
+	}
+	serve(t, handler, http.MethodGet, "/v1/expired", "", nil, http.StatusNotFound)
+	serve(t, handler, http.MethodGet, "/v1/alive", "", nil, http.StatusOK)
+}
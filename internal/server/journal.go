@@ -0,0 +1,126 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+const JOURNAL_MAX_ENTRIES = 1000
+
+// JournalEntry represents a request received on the mocked endpoints
+type JournalEntry struct {
+	Time       string             `json:"time"`
+	Method     string             `json:"method"`
+	URI        string             `json:"uri"`
+	Protocol   string             `json:"protocol"`
+	RemoteAddr string             `json:"remoteAddr"`
+	Headers    map[string]string  `json:"headers,omitempty"`
+	MockId     string             `json:"mockId,omitempty"`
+	Status     int                `json:"status"`
+	ClientCert *JournalClientCert `json:"clientCert,omitempty"`
+}
+
+// JournalClientCert represents the certificate presented by the client (mTLS)
+type JournalClientCert struct {
+	Subject      string   `json:"subject"`
+	Issuer       string   `json:"issuer"`
+	SerialNumber string   `json:"serialNumber"`
+	SANs         []string `json:"sans,omitempty"`
+	NotAfter     string   `json:"notAfter"`
+	Fingerprint  string   `json:"fingerprint"`
+}
+
+// Journal records the last requests received on the mocked endpoints
+type Journal struct {
+	mu      sync.Mutex
+	max     int
+	entries []JournalEntry
+}
+
+// NewJournal creates a {Journal} which keeps the {max} last entries
+func NewJournal(max int) *Journal {
+	return &Journal{max: max, entries: []JournalEntry{}}
+}
+
+// Record adds the entry to the journal and removes the oldest one if the journal is full.
+func (j *Journal) Record(entry JournalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries = append(j.entries, entry)
+	if len(j.entries) > j.max {
+		j.entries = j.entries[len(j.entries)-j.max:]
+	}
+}
+
+// Entries returns the recorded entries, the most recent first.
+func (j *Journal) Entries() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	values := make([]JournalEntry, len(j.entries))
+	for i, entry := range j.entries {
+		values[len(j.entries)-1-i] = entry
+	}
+	return values
+}
+
+// Clear removes all the entries.
+func (j *Journal) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries = []JournalEntry{}
+}
+
+func newJournalEntry(r *http.Request, remoteAddr, mockId string, status int) JournalEntry {
+	headers := map[string]string{}
+	for key := range r.Header {
+		headers[key] = r.Header.Get(key)
+	}
+
+	var clientCert *JournalClientCert
+	if cert := internal.ClientCertificate(r); cert != nil {
+		fingerprint := sha256.Sum256(cert.Raw)
+		clientCert = &JournalClientCert{
+			Subject:      cert.Subject.String(),
+			Issuer:       cert.Issuer.String(),
+			SerialNumber: cert.SerialNumber.String(),
+			SANs:         internal.CertificateSANs(cert),
+			NotAfter:     cert.NotAfter.Format(time.RFC3339),
+			Fingerprint:  hex.EncodeToString(fingerprint[:]),
+		}
+	}
+
+	return JournalEntry{
+		Time:       time.Now().Format("2006-01-02 15:04:05.000"),
+		Method:     r.Method,
+		URI:        r.RequestURI,
+		Protocol:   r.Proto,
+		RemoteAddr: remoteAddr,
+		Headers:    headers,
+		MockId:     mockId,
+		Status:     status,
+		ClientCert: clientCert,
+	}
+}
+
+func (s *HTTPServer) journalEntries(w http.ResponseWriter, r *http.Request) {
+	sc, err := s.scope(r)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		sc.target().journal.Clear()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.writeResponse(w, r, sc.target().journal.Entries(), http.StatusOK)
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// TestJournal calls Journal.Record, Journal.Entries and Journal.Clear,
+// checking for a valid return value.
+func TestJournal(t *testing.T) {
+	journal := NewJournal(2)
+
+	for _, uri := range []string{"/v1/1", "/v1/2", "/v1/3"} {
+		req := httptest.NewRequest("GET", uri, nil)
+		req.Header.Set("x-language", "golang")
+		journal.Record(newJournalEntry(req, "127.0.0.1", "{id}", 200))
+	}
+
+	entries := journal.Entries()
+	if len(entries) != 2 || entries[0].URI != "/v1/3" || entries[1].URI != "/v1/2" || entries[0].Headers["X-Language"] != "golang" {
+		t.Fatalf(`result: {%v} but expected {%v}`, entries, []string{"/v1/3", "/v1/2"})
+	}
+
+	journal.Clear()
+	if entries := journal.Entries(); len(entries) != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, entries, "[]")
+	}
+}
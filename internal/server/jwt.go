@@ -0,0 +1,272 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/go-utils/pkg/stringsutil"
+)
+
+var JWT_ALG_RS256 = "RS256"
+var JWT_ALG_ES256 = "ES256"
+var JWT_ALGS = []string{JWT_ALG_RS256, JWT_ALG_ES256}
+var JWT_TOKEN_TTL = time.Hour
+
+// JWTTokenRequest represents the body of the token-minting endpoint
+type JWTTokenRequest struct {
+	// Alg is the signing algorithm: {RS256} (default) or {ES256}
+	Alg string `json:"alg"`
+	// TTL is the time-to-live of the token (default {1h}), a negative value mints an expired token
+	TTL string `json:"ttl"`
+	// Claims are added to (or override) the default {iss}, {iat} and {exp} claims
+	Claims map[string]any `json:"claims"`
+}
+
+// JWTTokenResponse represents the response of the token-minting endpoint
+type JWTTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// JWTKeys represents the RSA and EC keys signing the JWT, generated on the first use
+type JWTKeys struct {
+	once sync.Once
+	rsa  *rsa.PrivateKey
+	ec   *ecdsa.PrivateKey
+	err  error
+}
+
+// NewJWTKeys creates an {JWTKeys} struct, the keys are generated on the first use
+func NewJWTKeys() *JWTKeys {
+	return &JWTKeys{}
+}
+
+func (k *JWTKeys) init() error {
+	k.once.Do(func() {
+		if k.rsa, k.err = rsa.GenerateKey(rand.Reader, 2048); k.err != nil {
+			return
+		}
+		k.ec, k.err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	})
+	return k.err
+}
+
+// kid returns the key identifier of the {alg}
+func kid(alg string) string {
+	return "mockapic-" + strings.ToLower(alg)
+}
+
+// JWKS returns the public keys in the JSON Web Key Set format.
+func (k *JWTKeys) JWKS() (map[string]any, error) {
+	if err := k.init(); err != nil {
+		return nil, err
+	}
+
+	ecdh, err := k.ec.PublicKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	ecPoint := ecdh.Bytes() // 0x04 || X || Y
+	encode := base64.RawURLEncoding.EncodeToString
+	return map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": JWT_ALG_RS256,
+			"kid": kid(JWT_ALG_RS256),
+			"n":   encode(k.rsa.N.Bytes()),
+			"e":   encode(big.NewInt(int64(k.rsa.E)).Bytes()),
+		}, {
+			"kty": "EC",
+			"use": "sig",
+			"alg": JWT_ALG_ES256,
+			"kid": kid(JWT_ALG_ES256),
+			"crv": "P-256",
+			"x":   encode(ecPoint[1:33]),
+			"y":   encode(ecPoint[33:]),
+		}},
+	}, nil
+}
+
+// Sign returns the JWT of the {claims} signed with the {alg} key.
+func (k *JWTKeys) Sign(alg string, claims map[string]any) (string, error) {
+	if !slicesutil.Exist(JWT_ALGS, alg) {
+		return "", fmt.Errorf("alg {%s} is not valid", alg)
+	}
+	if err := k.init(); err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid(alg)})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	if alg == JWT_ALG_RS256 {
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, hash[:])
+	} else {
+		var r, s *big.Int
+		if r, s, err = ecdsa.Sign(rand.Reader, k.ec, hash[:]); err == nil {
+			signature = make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks the signature of the {token} and returns its claims.
+func (k *JWTKeys) Verify(token string) (map[string]any, error) {
+	if err := k.init(); err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a valid JWT")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("token signature is not valid")
+	}
+
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	switch header.Alg {
+	case JWT_ALG_RS256:
+		if rsa.VerifyPKCS1v15(&k.rsa.PublicKey, crypto.SHA256, hash[:], signature) != nil {
+			return nil, errors.New("token signature is not valid")
+		}
+	case JWT_ALG_ES256:
+		if len(signature) != 64 || !ecdsa.Verify(&k.ec.PublicKey, hash[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+			return nil, errors.New("token signature is not valid")
+		}
+	default:
+		return nil, fmt.Errorf("token alg {%s} is not valid", header.Alg)
+	}
+
+	claims := map[string]any{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("token is not a valid JWT")
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return errors.New("token is not a valid JWT")
+	}
+	return nil
+}
+
+// issuer returns the base url of the server used as {iss} claim
+func (s *HTTPServer) issuer(r *http.Request) string {
+	return s.getProtocol(r) + "://" + r.Host
+}
+
+// jwks returns the public keys of the signed JWT.
+func (s *HTTPServer) jwks(w http.ResponseWriter, r *http.Request) {
+	keys, err := s.jwtKeys.JWKS()
+	if err != nil {
+		s.logger.Error(err, "error to generate the jwt keys", "uri", r.RequestURI)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+	s.writeResponse(w, r, keys, http.StatusOK)
+}
+
+// openIDConfiguration returns the OpenID Connect discovery document of the mocked identity provider.
+func (s *HTTPServer) openIDConfiguration(w http.ResponseWriter, r *http.Request) {
+	issuer := s.issuer(r)
+	s.writeResponse(w, r, map[string]any{
+		"issuer":                                issuer,
+		"jwks_uri":                              issuer + "/.well-known/jwks.json",
+		"token_endpoint":                        issuer + "/oauth/token",
+		"grant_types_supported":                 []string{"client_credentials", "refresh_token"},
+		"id_token_signing_alg_values_supported": JWT_ALGS,
+	}, http.StatusOK)
+}
+
+// jwtToken mints a JWT with the claims of the request body signed with the {alg} key,
+// the default claims are {iss} (the server url), {iat} and {exp}.
+func (s *HTTPServer) jwtToken(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.logger.Error(err, "error to read body", "uri", r.RequestURI)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	request := JWTTokenRequest{}
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if request, err = jsonsutil.Unmarshal[JWTTokenRequest](data); err != nil {
+			writeError(w, fmt.Errorf("body is not valid: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ttl := JWT_TOKEN_TTL
+	if request.TTL != "" {
+		if ttl, err = time.ParseDuration(request.TTL); err != nil {
+			writeError(w, fmt.Errorf("ttl {%s} is not valid", request.TTL), http.StatusBadRequest)
+			return
+		}
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss": s.issuer(r),
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+	for name, value := range request.Claims {
+		claims[name] = value
+	}
+
+	token, err := s.jwtKeys.Sign(stringsutil.OrElse(request.Alg, JWT_ALG_RS256), claims)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	s.writeResponse(w, r, JWTTokenResponse{AccessToken: token, TokenType: "Bearer", ExpiresIn: int(ttl.Seconds())}, http.StatusOK)
+}
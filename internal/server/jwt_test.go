@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestJWTKeys calls JWTKeys.Sign and JWTKeys.Verify,
+// checking for a valid return value.
+func TestJWTKeys(t *testing.T) {
+	keys := NewJWTKeys()
+
+	if _, err := keys.Sign("HS256", map[string]any{}); err == nil || err.Error() != "alg {HS256} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "alg {HS256} is not valid")
+	}
+
+	for _, alg := range JWT_ALGS {
+		token, err := keys.Sign(alg, map[string]any{"sub": "user"})
+		if err != nil {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+		}
+		claims, err := keys.Verify(token)
+		if err != nil || claims["sub"] != "user" {
+			t.Fatalf(`result: {%v} but expected {%v}`, claims, "user")
+		}
+
+		// the payload is replaced by another one
+		parts := strings.Split(token, ".")
+		other, _ := keys.Sign(alg, map[string]any{"sub": "admin"})
+		if _, err := keys.Verify(parts[0] + "." + strings.Split(other, ".")[1] + "." + parts[2]); err == nil {
+			t.Fatalf(`result: {%v} but expected error`, err)
+		}
+	}
+
+	// the token is signed by other keys
+	token, _ := NewJWTKeys().Sign(JWT_ALG_ES256, map[string]any{"sub": "user"})
+	if _, err := keys.Verify(token); err == nil || err.Error() != "token signature is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "token signature is not valid")
+	}
+	if _, err := keys.Verify("token"); err == nil || err.Error() != "token is not a valid JWT" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "token is not a valid JWT")
+	}
+}
+
+// TestJWTEndpoints calls HTTPServer.jwks, HTTPServer.jwtToken and a mocked request with a {jwt} authentication,
+// checking for a valid return value.
+func TestJWTEndpoints(t *testing.T) {
+	mock := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, mock, *logger, "test").handler()
+
+	mint := func(body string) string {
+		response, _ := jsonsutil.Unmarshal[JWTTokenResponse](serve(t, handler, http.MethodPost, "/jwt/token", body, nil, http.StatusOK).Body.Bytes())
+		return response.AccessToken
+	}
+
+	jwks, _ := jsonsutil.Unmarshal[map[string][]map[string]string](serve(t, handler, http.MethodGet, "/.well-known/jwks.json", "", nil, http.StatusOK).Body.Bytes())
+	if len(jwks["keys"]) != 2 || jwks["keys"][0]["kty"] != "RSA" || jwks["keys"][1]["crv"] != "P-256" || len(jwks["keys"][1]["x"]) != 43 {
+		t.Fatalf(`result: {%v} but expected {%v}`, jwks, "RSA and EC keys")
+	}
+	configuration, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodGet, "/.well-known/openid-configuration", "", nil, http.StatusOK).Body.Bytes())
+	if configuration["issuer"] != "http://localhost:3333" || configuration["jwks_uri"] != "http://localhost:3333/.well-known/jwks.json" {
+		t.Fatalf(`result: {%v} but expected {%v}`, configuration, "http://localhost:3333")
+	}
+
+	serve(t, handler, http.MethodPost, "/jwt/token", `{"alg":"HS256"}`, nil, http.StatusBadRequest)
+	serve(t, handler, http.MethodPost, "/jwt/token", `{"ttl":"1 hour"}`, nil, http.StatusBadRequest)
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/orders&auth=jwt&authAudience=orders&authClaims=sub,role=admin", "orders", nil, http.StatusCreated)
+
+	serve(t, handler, http.MethodGet, "/v1/orders", "", nil, http.StatusUnauthorized)
+	serve(t, handler, http.MethodGet, "/v1/orders", "", http.Header{"Authorization": {"Bearer token"}}, http.StatusUnauthorized)
+	serve(t, handler, http.MethodGet, "/v1/orders", "", http.Header{"Authorization": {"Bearer " + mint(`{"ttl":"-1m","claims":{"aud":"orders","sub":"user","role":"admin"}}`)}}, http.StatusUnauthorized)
+	serve(t, handler, http.MethodGet, "/v1/orders", "", http.Header{"Authorization": {"Bearer " + mint(`{"claims":{"aud":"payments","sub":"user","role":"admin"}}`)}}, http.StatusUnauthorized)
+	serve(t, handler, http.MethodGet, "/v1/orders", "", http.Header{"Authorization": {"Bearer " + mint(`{"claims":{"aud":"orders","sub":"user","role":"user"}}`)}}, http.StatusForbidden)
+	if w := serve(t, handler, http.MethodGet, "/v1/orders", "", http.Header{"Authorization": {"Bearer " + mint(`{"alg":"ES256","claims":{"aud":["orders"],"sub":"user","role":"admin"}}`)}}, http.StatusOK); w.Body.String() != "orders" {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Body.String(), "orders")
+	}
+}
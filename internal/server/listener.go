@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+)
+
+type boundContextKey struct{}
+
+// Listener represents a port of the server in http or https (TLS),
+// the requests are bound to the {Namespace} if it is not empty
+type Listener struct {
+	Port      string
+	TLS       bool
+	Namespace string
+}
+
+// String returns the url scheme and the port of the listener (e.g. {https[:3443]})
+func (l Listener) String() string {
+	scheme := "http"
+	if l.TLS {
+		scheme = "https"
+	}
+	if l.Namespace != "" {
+		return fmt.Sprintf("%s[:%s] -> /ns/%s", scheme, l.Port, l.Namespace)
+	}
+	return fmt.Sprintf("%s[:%s]", scheme, l.Port)
+}
+
+// ParseListeners parses the additional ports {value} formatted as [tls:]{port}={namespace},...
+func ParseListeners(value string) ([]Listener, error) {
+	listeners := []Listener{}
+	for _, entry := range slicesutil.FilterByNonEmpty(strings.Split(value, ",")) {
+		entry, tls := strings.CutPrefix(strings.TrimSpace(entry), "tls:")
+		port, namespace, _ := strings.Cut(entry, "=")
+		if nb, err := strconv.Atoi(port); err != nil || nb < 1 || nb > 65535 {
+			return nil, fmt.Errorf("port {%s} is not valid", port)
+		}
+		if !NAMESPACE_NAME.MatchString(namespace) {
+			return nil, fmt.Errorf("namespace {%s} of port {%s} is not valid", namespace, port)
+		}
+		listeners = append(listeners, Listener{Port: port, TLS: tls, Namespace: namespace})
+	}
+	return listeners, nil
+}
+
+// Listeners returns the ports of the server: the {Port} in http (or in https if the SSL mode is enabled without a specific port),
+// the SSL port in https and the additional ports.
+func (s *HTTPServer) Listeners() []Listener {
+	listeners := []Listener{{Port: s.Port, TLS: s.ssl.enabled && s.ssl.port == ""}}
+	if s.ssl.enabled && s.ssl.port != "" {
+		listeners = append(listeners, Listener{Port: s.ssl.port, TLS: true})
+	}
+	return append(listeners, s.listeners...)
+}
+
+// bind dispatches the requests of the {handler} to the {namespace}, it is created on the first request if it does not exist.
+func (s *HTTPServer) bind(handler http.Handler, namespace string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.namespaces == nil {
+			s.logRequest(r)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		ns, ok := s.namespaces.Get(namespace)
+		if !ok {
+			var err error
+			if ns, err = s.namespaces.Open(namespace, -1); err != nil {
+				s.logRequest(r)
+				s.logger.Error(err, "error to open namespace", "uri", r.RequestURI, "namespace", namespace)
+				writeError(w, err, http.StatusInternalServerError)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), boundContextKey{}, namespace)
+		handler.ServeHTTP(w, withNamespace(r.WithContext(ctx), ns))
+	})
+}
+
+// boundNamespace returns the namespace of the port which receives the request {r}, or an empty string.
+func boundNamespace(r *http.Request) string {
+	value, _ := r.Context().Value(boundContextKey{}).(string)
+	return value
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestParseListeners calls ParseListeners,
+// checking for a valid return value.
+func TestParseListeners(t *testing.T) {
+	listeners, err := ParseListeners("3334=team-a, tls:3444=team-b,")
+	if err != nil || len(listeners) != 2 ||
+		listeners[0] != (Listener{Port: "3334", Namespace: "team-a"}) ||
+		listeners[1] != (Listener{Port: "3444", TLS: true, Namespace: "team-b"}) {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, listeners, err, "team-a and team-b")
+	}
+
+	for value, expected := range map[string]string{
+		"port=team-a": "port {port} is not valid",
+		"70000=team":  "port {70000} is not valid",
+		"3334":        "namespace {} of port {3334} is not valid",
+		"3334=a/b":    "namespace {a/b} of port {3334} is not valid",
+	} {
+		if _, err := ParseListeners(value); err == nil || err.Error() != expected {
+			t.Fatalf(`result: {%v} but expected {%v}`, err, expected)
+		}
+	}
+}
+
+// TestListeners calls HTTPServer.Listeners,
+// checking for a valid return value.
+func TestListeners(t *testing.T) {
+	newHTTPServer := func(ssl SSL) *HTTPServer {
+		return NewHTTPServer("3333", ssl, workingDirectory, -1, &MockerTest{}, *logger, "test")
+	}
+
+	if l := newHTTPServer(NewSSL(false, "", "", "")).Listeners(); len(l) != 1 || l[0].String() != "http[:3333]" {
+		t.Fatalf(`result: {%v} but expected {%v}`, l, "http[:3333]")
+	}
+	if l := newHTTPServer(NewSSL(true, "", "", "")).Listeners(); len(l) != 1 || l[0].String() != "https[:3333]" {
+		t.Fatalf(`result: {%v} but expected {%v}`, l, "https[:3333]")
+	}
+	l := newHTTPServer(NewSSL(true, "", "", "").WithPort("3443")).WithListeners([]Listener{{Port: "3334", Namespace: "team-a"}}).Listeners()
+	if len(l) != 3 || l[0].String() != "http[:3333]" || l[1].String() != "https[:3443]" || l[2].String() != "http[:3334] -> /ns/team-a" {
+		t.Fatalf(`result: {%v} but expected {%v}`, l, "http[:3333], https[:3443] and http[:3334]")
+	}
+}
+
+// TestBind calls HTTPServer.bind on a namespace,
+// checking for a valid return value.
+func TestBind(t *testing.T) {
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test").
+		WithNamespaces(NewNamespaces(internal.NewMemoryStorage(*logger), -1, *logger))
+	handler := s.handler()
+	bound := s.bind(handler, "team-a")
+
+	// the namespace is created on the first request and the links are not prefixed on the bound port
+	w := serve(t, bound, http.MethodPost, "https://localhost:3444/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hello", "team-a", nil, http.StatusCreated)
+	created, _ := jsonsutil.Unmarshal[map[string]any](w.Body.Bytes())
+	links := created["_links"].(map[string]any)
+	if links["path"] != "https://localhost:3444/v1/hello" {
+		t.Fatalf(`result: {%v} but expected {%v}`, links, "https://localhost:3444/v1/hello")
+	}
+
+	if w := serve(t, bound, http.MethodGet, "http://localhost:3334/v1/hello", "", nil, http.StatusOK); w.Body.String() != "team-a" {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Body.String(), "team-a")
+	}
+	serve(t, handler, http.MethodGet, "http://localhost:3333/ns/team-a/v1/hello", "", nil, http.StatusOK)
+	serve(t, handler, http.MethodGet, "http://localhost:3333/v1/hello", "", nil, http.StatusNotFound)
+}
+
+// TestListenHTTPAndHTTPS calls HTTPServer.Listen() with a http and a https port,
+// checking for a valid return value.
+func TestListenHTTPAndHTTPS(t *testing.T) {
+	freePort := func() string {
+		l, _ := net.Listen("tcp", ":0")
+		defer l.Close()
+		_, port, _ := net.SplitHostPort(l.Addr().String())
+		return port
+	}
+	httpPort, httpsPort := freePort(), freePort()
+
+	mock := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+	s := NewHTTPServer(httpPort, NewSSL(true, t.TempDir(), "", "").WithPort(httpsPort), workingDirectory, -1, mock, *logger, "test")
+	created, _ := mock.New(map[string][]string{"status": {"200"}, "contentType": {"text/plain"}, "charset": {"UTF-8"}}, []byte("Hello World"))
+
+	go func() {
+		if err := s.Listen(); err != nil {
+			t.Errorf("Error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	for _, url := range []string{"http://localhost:" + httpPort, "https://localhost:" + httpsPort} {
+		resp, err := client.Get(url + "/v1/" + created.Id)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf(`result: {%v} {%v} but expected {%v}`, resp, err, http.StatusOK)
+		}
+		resp.Body.Close()
+	}
+}
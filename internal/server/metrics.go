@@ -0,0 +1,228 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+var METRICS_DURATION_BUCKETS = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+var METRICS_DELAY_BUCKETS = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// histogram represents the cumulative buckets, the sum and the count of the observed values
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(value float64) {
+	for i, bucket := range h.buckets {
+		if value <= bucket {
+			h.counts[i]++
+		}
+	}
+	h.sum = h.sum + value
+	h.count++
+}
+
+// write writes the {name}_bucket, {name}_sum and {name}_count samples of the histogram with the {labels}.
+func (h *histogram) write(w io.Writer, name, labels string) {
+	separator := labelSeparator(labels)
+	for i, bucket := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, separator, strconv.FormatFloat(bucket, 'g', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, separator, h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, braces(labels), strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, braces(labels), h.count)
+}
+
+func labelSeparator(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return ","
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// requestLabels represents the labels of the calls to a mocked request
+type requestLabels struct {
+	mockId string
+	path   string
+	status string
+}
+
+func (l requestLabels) String() string {
+	return fmt.Sprintf(`mock_id="%s",path="%s",status="%s"`, escapeLabel(l.mockId), escapeLabel(l.path), escapeLabel(l.status))
+}
+
+// Metrics represents the counters and the histograms of the server exposed in the Prometheus text format
+type Metrics struct {
+	mu        sync.Mutex
+	requests  map[requestLabels]uint64
+	durations map[requestLabels]*histogram
+	unmatched uint64
+	evictions uint64
+	created   map[string]uint64
+	delays    *histogram
+}
+
+// NewMetrics creates and initializes a {Metrics} struct
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:  map[requestLabels]uint64{},
+		durations: map[requestLabels]*histogram{},
+		created:   map[string]uint64{},
+		delays:    newHistogram(METRICS_DELAY_BUCKETS),
+	}
+}
+
+// ObserveRequest counts a call to the mocked request {mockId} answered with the {status} in {duration}.
+func (m *Metrics) ObserveRequest(mockId, path string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := requestLabels{mockId: mockId, path: path, status: strconv.Itoa(status)}
+	m.requests[labels]++
+	if _, ok := m.durations[labels]; !ok {
+		m.durations[labels] = newHistogram(METRICS_DURATION_BUCKETS)
+	}
+	m.durations[labels].observe(duration.Seconds())
+}
+
+// ObserveUnmatched counts a call which does not match any mocked request.
+func (m *Metrics) ObserveUnmatched() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.unmatched++
+}
+
+// ObserveEvictions counts the {nb} mocked requests removed over the max limit.
+func (m *Metrics) ObserveEvictions(nb int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evictions = m.evictions + uint64(nb)
+}
+
+// ObserveCreated counts a mocked request created by the {client}.
+func (m *Metrics) ObserveCreated(client string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.created[client]++
+}
+
+// ObserveDelay observes the {delay} injected in a response.
+func (m *Metrics) ObserveDelay(delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.delays.observe(delay.Seconds())
+}
+
+// Write writes the metrics and the number of stored {mocks} by namespace in the Prometheus text format.
+func (m *Metrics) Write(w io.Writer, mocks map[string]int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	header := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	labels := make([]requestLabels, 0, len(m.requests))
+	for l := range m.requests {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].String() < labels[j].String() })
+
+	header("mockapic_requests_total", "counter", "Number of calls to the mocked requests.")
+	for _, l := range labels {
+		fmt.Fprintf(w, "mockapic_requests_total{%s} %d\n", l, m.requests[l])
+	}
+
+	header("mockapic_request_duration_seconds", "histogram", "Duration of the calls to the mocked requests.")
+	for _, l := range labels {
+		m.durations[l].write(w, "mockapic_request_duration_seconds", l.String())
+	}
+
+	header("mockapic_unmatched_requests_total", "counter", "Number of calls which do not match any mocked request.")
+	fmt.Fprintf(w, "mockapic_unmatched_requests_total %d\n", m.unmatched)
+
+	header("mockapic_delay_seconds", "histogram", "Delay injected in the responses of the mocked requests.")
+	m.delays.write(w, "mockapic_delay_seconds", "")
+
+	header("mockapic_mocks", "gauge", "Number of stored mocked requests.")
+	for _, namespace := range sortedKeys(mocks) {
+		fmt.Fprintf(w, "mockapic_mocks{namespace=\"%s\"} %d\n", escapeLabel(namespace), mocks[namespace])
+	}
+
+	header("mockapic_evictions_total", "counter", "Number of mocked requests removed over the max limit.")
+	fmt.Fprintf(w, "mockapic_evictions_total %d\n", m.evictions)
+
+	header("mockapic_mocks_created_total", "counter", "Number of mocked requests created by client.")
+	for _, client := range sortedKeys(m.created) {
+		fmt.Fprintf(w, "mockapic_mocks_created_total{client=\"%s\"} %d\n", escapeLabel(client), m.created[client])
+	}
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapeLabel escapes the backslashes, the double quotes and the line feeds of a label {value}.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// metricsPath returns the configured path of the {mock} or the {/v1/{id}} template,
+// the path of the request is not used to keep the number of metric series bounded.
+func metricsPath(mock *internal.MockedRequest) string {
+	if mock.Path != "" {
+		return "/v1" + mock.Path
+	}
+	return "/v1/{id}"
+}
+
+// getMetrics writes the metrics of the server in the Prometheus text format.
+func (s *HTTPServer) getMetrics(w http.ResponseWriter, r *http.Request) {
+	namespaces := []*Namespace{s.defaultNamespace()}
+	if s.namespaces != nil {
+		namespaces = append(namespaces, s.namespaces.List()...)
+	}
+
+	mocks := map[string]int{}
+	for _, ns := range namespaces {
+		if values, err := ns.mocker.List(); err == nil {
+			mocks[ns.Name] = len(values)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	s.metrics.Write(w, mocks)
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestMetricsWrite calls Metrics.Write,
+// checking for a valid return value.
+func TestMetricsWrite(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.ObserveRequest("id-1", "/v1/id-1", 200, 20*time.Millisecond)
+	metrics.ObserveRequest("id-1", "/v1/id-1", 200, 2*time.Second)
+	metrics.ObserveRequest("id-2", `/v1/"quoted"`, 404, time.Millisecond)
+	metrics.ObserveUnmatched()
+	metrics.ObserveEvictions(2)
+	metrics.ObserveCreated("127.0.0.1")
+	metrics.ObserveCreated("127.0.0.1")
+	metrics.ObserveDelay(300 * time.Millisecond)
+
+	var buffer bytes.Buffer
+	metrics.Write(&buffer, map[string]int{"": 3, "team-a": 1})
+	result := buffer.String()
+
+	for _, expected := range []string{
+		"# TYPE mockapic_requests_total counter\n",
+		`mockapic_requests_total{mock_id="id-1",path="/v1/id-1",status="200"} 2` + "\n",
+		`mockapic_requests_total{mock_id="id-2",path="/v1/\"quoted\"",status="404"} 1` + "\n",
+		"# TYPE mockapic_request_duration_seconds histogram\n",
+		`mockapic_request_duration_seconds_bucket{mock_id="id-1",path="/v1/id-1",status="200",le="0.025"} 1` + "\n",
+		`mockapic_request_duration_seconds_bucket{mock_id="id-1",path="/v1/id-1",status="200",le="+Inf"} 2` + "\n",
+		`mockapic_request_duration_seconds_count{mock_id="id-1",path="/v1/id-1",status="200"} 2` + "\n",
+		"mockapic_unmatched_requests_total 1\n",
+		`mockapic_delay_seconds_bucket{le="0.25"} 0` + "\n",
+		`mockapic_delay_seconds_bucket{le="0.5"} 1` + "\n",
+		"mockapic_delay_seconds_sum 0.3\n",
+		"mockapic_delay_seconds_count 1\n",
+		`mockapic_mocks{namespace=""} 3` + "\n",
+		`mockapic_mocks{namespace="team-a"} 1` + "\n",
+		"mockapic_evictions_total 2\n",
+		`mockapic_mocks_created_total{client="127.0.0.1"} 2` + "\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Fatalf(`result: {%v} but expected {%v}`, result, expected)
+		}
+	}
+}
+
+// TestMetricsEndpoint calls HTTPServer.getMetrics,
+// checking for a valid return value.
+func TestMetricsEndpoint(t *testing.T) {
+	mock := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+	directory := t.TempDir()
+	os.MkdirAll(filepath.Join(directory, "files"), os.ModePerm)
+	os.WriteFile(filepath.Join(directory, "files", "user.json"), []byte(`{"id":1}`), 0644)
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), directory, 2, mock, *logger, "test")
+	handler := s.handler()
+
+	created, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodPost, "/v1/new?status=202&contentType=text/plain&charset=UTF-8&path=/first", "", nil, http.StatusCreated).Body.Bytes())
+	serve(t, handler, http.MethodGet, "/v1/first?delay=10ms", "", nil, http.StatusAccepted)
+	serve(t, handler, http.MethodGet, "/v1/unknown", "", nil, http.StatusNotFound)
+
+	// the mocked requests based on the http status code are labelled with the path template
+	serve(t, handler, http.MethodGet, "/v1/any/path/200", "", nil, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/v1/other/200", "", nil, http.StatusOK)
+
+	// the body file cannot be read anymore
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&path=/user&bodyFile=user.json", "", nil, http.StatusCreated)
+	os.Remove(filepath.Join(directory, "files", "user.json"))
+	serve(t, handler, http.MethodGet, "/v1/user", "", nil, http.StatusInternalServerError)
+
+	// the max limit is reached, a mocked request is removed
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/second", "", nil, http.StatusCreated)
+
+	w := serve(t, handler, http.MethodGet, "/metrics", "", nil, http.StatusOK)
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	}
+
+	result := w.Body.String()
+	for _, expected := range []string{
+		`mockapic_requests_total{mock_id="` + created["id"].(string) + `",path="/v1/first",status="202"} 1` + "\n",
+		`mockapic_requests_total{mock_id="200",path="/v1/{id}",status="200"} 2` + "\n",
+		`path="/v1/user",status="500"} 1` + "\n",
+		"mockapic_unmatched_requests_total 1\n",
+		"mockapic_delay_seconds_count 1\n",
+		`mockapic_mocks{namespace=""} 2` + "\n",
+		"mockapic_evictions_total 1\n",
+		`mockapic_mocks_created_total{client="192.0.2.1"} 3` + "\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Fatalf(`result: {%v} but expected {%v}`, result, expected)
+		}
+	}
+}
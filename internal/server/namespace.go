@@ -0,0 +1,304 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
+	"github.com/joakim-ribier/go-utils/pkg/logsutil"
+	"github.com/joakim-ribier/go-utils/pkg/stringsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+var NAMESPACE_NAME = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type namespaceContextKey struct{}
+
+// Namespace represents an isolated set of mocked requests
+type Namespace struct {
+	mockSpace
+	Name                       string
+	sessions                   *Sessions
+	totalNumberRequestsAllowed int
+}
+
+// newNamespace creates a {Namespace} and loads the path of its existing mocked requests
+func newNamespace(name string, mocker internal.Mocker, totalNumberRequestsAllowed int, logger logsutil.Logger) *Namespace {
+	ns := &Namespace{
+		mockSpace: mockSpace{
+			mocker:  mocker,
+			routes:  NewRoutes(),
+			journal: NewJournal(JOURNAL_MAX_ENTRIES),
+		},
+		Name:                       name,
+		sessions:                   NewSessions(logger),
+		totalNumberRequestsAllowed: totalNumberRequestsAllowed,
+	}
+
+	if values, err := mocker.List(); err == nil {
+		for _, value := range values {
+			if value.Path != "" {
+				ns.routes.Set("/v1"+value.Path, value.Id)
+			}
+		}
+	}
+	return ns
+}
+
+// prefix returns the URL prefix of the namespace
+func (ns *Namespace) prefix() string {
+	if ns.Name == "" {
+		return ""
+	}
+	return "/ns/" + ns.Name
+}
+
+// Namespaces represents the namespaces opened from the {internal.Storage}
+type Namespaces struct {
+	mu                         sync.RWMutex
+	logger                     logsutil.Logger
+	storage                    internal.Storage
+	totalNumberRequestsAllowed int
+	values                     map[string]*Namespace
+}
+
+// NewNamespaces creates and initializes a {Namespaces} struct
+func NewNamespaces(storage internal.Storage, totalNumberRequestsAllowed int, logger logsutil.Logger) *Namespaces {
+	return &Namespaces{
+		logger:                     logger.Namespace("namespace"),
+		storage:                    storage,
+		totalNumberRequestsAllowed: totalNumberRequestsAllowed,
+		values:                     map[string]*Namespace{},
+	}
+}
+
+// Load opens all the existing namespaces of the storage.
+func (n *Namespaces) Load() (int, error) {
+	names, err := n.storage.Namespaces()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, name := range names {
+		if NAMESPACE_NAME.MatchString(name) {
+			if _, err := n.Open(name, n.totalNumberRequestsAllowed); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(n.values), nil
+}
+
+// Get returns the namespace {name} if it is opened.
+func (n *Namespaces) Get(name string) (*Namespace, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	ns, ok := n.values[name]
+	return ns, ok
+}
+
+// Open returns the namespace {name} and creates it if it does not exist,
+// a {totalNumberRequestsAllowed} > 0 overrides its max limit.
+func (n *Namespaces) Open(name string, totalNumberRequestsAllowed int) (*Namespace, error) {
+	if !NAMESPACE_NAME.MatchString(name) {
+		return nil, fmt.Errorf("namespace {%s} is not valid", name)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if ns, ok := n.values[name]; ok {
+		if totalNumberRequestsAllowed > 0 {
+			ns.totalNumberRequestsAllowed = totalNumberRequestsAllowed
+		}
+		return ns, nil
+	}
+
+	mocker, err := n.storage.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if totalNumberRequestsAllowed < 1 {
+		totalNumberRequestsAllowed = n.totalNumberRequestsAllowed
+	}
+	ns := newNamespace(name, mocker, totalNumberRequestsAllowed, n.logger)
+	n.values[name] = ns
+
+	return ns, nil
+}
+
+// Drop removes the namespace {name} and all its mocked requests.
+func (n *Namespaces) Drop(name string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, ok := n.values[name]; !ok {
+		return errors.New("namespace does not exist")
+	}
+	if err := n.storage.Drop(name); err != nil {
+		return err
+	}
+	delete(n.values, name)
+	return nil
+}
+
+// List returns the opened namespaces sorted by name.
+func (n *Namespaces) List() []*Namespace {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	values := make([]*Namespace, 0, len(n.values))
+	for _, ns := range n.values {
+		values = append(values, ns)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+// withNamespace returns a shallow copy of the request {r} bound to the namespace {ns}
+func withNamespace(r *http.Request, ns *Namespace) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), namespaceContextKey{}, ns))
+}
+
+type NamespaceWithLinks struct {
+	Name                       string            `json:"name"`
+	TotalNumberRequestsAllowed int               `json:"reqMax"`
+	TotalNumberRequests        int               `json:"total"`
+	Links                      map[string]string `json:"_links,omitempty"`
+}
+
+func (s *HTTPServer) toNamespaceWithLinks(r *http.Request, ns *Namespace) NamespaceWithLinks {
+	total := 0
+	if values, err := ns.mocker.List(); err == nil {
+		total = len(values)
+	}
+
+	baseURL := s.getProtocol(r) + "://" + r.Host + ns.prefix()
+	return NamespaceWithLinks{
+		Name:                       ns.Name,
+		TotalNumberRequestsAllowed: ns.totalNumberRequestsAllowed,
+		TotalNumberRequests:        total,
+		Links: map[string]string{
+			"self": baseURL,
+			"list": baseURL + "/v1/list",
+			"new":  baseURL + "/v1/new",
+		},
+	}
+}
+
+// serveNamespace dispatches the {/ns/{name}/v1/...} requests to the {/v1/...} handlers
+// bound to the namespace and handles the namespace management requests {/ns/{name}}.
+func (s *HTTPServer) serveNamespace(handler http.Handler, w http.ResponseWriter, r *http.Request) {
+	name, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/ns/"), "/")
+
+	rest := sub
+	if strings.HasPrefix(sub, "session/") {
+		// {/ns/{name}/session/{id}/v1/...} requests are dispatched to the session handler
+		_, rest, _ = strings.Cut(strings.TrimPrefix(sub, "session/"), "/")
+	}
+
+	if s.namespaces == nil || (sub != "" && !strings.HasPrefix(rest, "v1/")) {
+		s.logRequest(r)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if sub == "" {
+		s.logRequest(r)
+		if !s.authorize(w, r) {
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.getNamespace(w, r, name)
+		case http.MethodPut:
+			s.openNamespace(w, r, name)
+		case http.MethodDelete:
+			s.dropNamespace(w, r, name)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return
+	}
+
+	ns, ok := s.namespaces.Get(name)
+	if !ok && r.Method == http.MethodPost && (rest == "v1/new" || rest == "v1/sessions" || strings.HasPrefix(rest, "v1/import/")) {
+		if !s.authorize(w, r) {
+			s.logRequest(r)
+			return
+		}
+		var err error
+		if ns, err = s.namespaces.Open(name, -1); err != nil {
+			s.logRequest(r)
+			s.logger.Error(err, "error to open namespace", "uri", r.RequestURI, "namespace", name)
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+	} else if !ok {
+		s.logRequest(r)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	r = withNamespace(r, ns)
+	u := *r.URL
+	u.Path = "/" + sub
+	u.RawPath = ""
+	r.URL = &u
+
+	handler.ServeHTTP(w, r)
+}
+
+func (s *HTTPServer) listNamespaces(w http.ResponseWriter, r *http.Request) {
+	values := []NamespaceWithLinks{}
+	if s.namespaces != nil {
+		for _, ns := range s.namespaces.List() {
+			values = append(values, s.toNamespaceWithLinks(r, ns))
+		}
+	}
+	s.writeResponse(w, r, values, http.StatusOK)
+}
+
+func (s *HTTPServer) getNamespace(w http.ResponseWriter, r *http.Request, name string) {
+	ns, ok := s.namespaces.Get(name)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.writeResponse(w, r, s.toNamespaceWithLinks(r, ns), http.StatusOK)
+}
+
+func (s *HTTPServer) openNamespace(w http.ResponseWriter, r *http.Request, name string) {
+	_, exist := s.namespaces.Get(name)
+
+	ns, err := s.namespaces.Open(name, stringsutil.Int(r.URL.Query().Get("req_max"), -1))
+	if err != nil {
+		s.logger.Error(err, "error to open namespace", "uri", r.RequestURI, "namespace", name)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	s.writeResponse(w, r, s.toNamespaceWithLinks(r, ns), genericsutil.When(exist, func(b bool) bool { return b }, http.StatusOK, http.StatusCreated))
+}
+
+func (s *HTTPServer) dropNamespace(w http.ResponseWriter, r *http.Request, name string) {
+	if _, ok := s.namespaces.Get(name); !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := s.namespaces.Drop(name); err != nil {
+		s.logger.Error(err, "error to drop namespace", "uri", r.RequestURI, "namespace", name)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestNamespaces calls Namespaces.Open, Namespaces.Get, Namespaces.List, Namespaces.Drop and Namespaces.Load,
+// checking for a valid return value.
+func TestNamespaces(t *testing.T) {
+	storage := internal.NewFileStorage(t.TempDir(), *logger)
+	namespaces := NewNamespaces(storage, 10, *logger)
+
+	if _, err := namespaces.Open("bad/name", -1); err == nil || err.Error() != "namespace {bad/name} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "namespace {bad/name} is not valid")
+	}
+
+	ns, err := namespaces.Open("team-a", -1)
+	if err != nil || ns.totalNumberRequestsAllowed != 10 {
+		t.Fatalf(`result: {%v} but expected {%v}`, ns, 10)
+	}
+
+	mock, err := ns.mocker.New(map[string][]string{
+		"status":      {"200"},
+		"contentType": {"text/plain"},
+		"charset":     {"UTF-8"},
+		"path":        {"/hello"},
+	}, []byte("Hello World"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// override the max limit of an existing namespace
+	if ns, _ := namespaces.Open("team-a", 2); ns.totalNumberRequestsAllowed != 2 {
+		t.Fatalf(`result: {%v} but expected {%v}`, ns.totalNumberRequestsAllowed, 2)
+	}
+	namespaces.Open("team-b", -1)
+
+	if values := namespaces.List(); len(values) != 2 || values[0].Name != "team-a" || values[1].Name != "team-b" {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, []string{"team-a", "team-b"})
+	}
+
+	// load the existing namespaces and their paths from the storage
+	loaded := NewNamespaces(storage, 10, *logger)
+	if nb, err := loaded.Load(); err != nil || nb != 2 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 2)
+	}
+	ns, ok := loaded.Get("team-a")
+	if !ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, true)
+	}
+	if mockIds, _ := ns.routes.Get("/v1/hello"); len(mockIds) != 1 || mockIds[0] != mock.Id {
+		t.Fatalf(`result: {%v} but expected {%v}`, mockIds, mock.Id)
+	}
+
+	if err := namespaces.Drop("team-a"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, ok := namespaces.Get("team-a"); ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, false)
+	}
+	if err := namespaces.Drop("team-a"); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+}
+
+// TestNamespaceEndpoints calls HTTPServer.serveNamespace(http.Handler, http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestNamespaceEndpoints(t *testing.T) {
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, &MockerTest{}, *logger, "test").
+		WithNamespaces(NewNamespaces(internal.NewFileStorage(t.TempDir(), *logger), -1, *logger))
+	handler := s.handler()
+
+	if r := serve(t, handler, http.MethodPut, "/ns/team-a?req_max=2", "", nil, http.StatusCreated).Body.String(); r != `{"name":"team-a","reqMax":2,"total":0,"_links":{"list":"http://localhost:3333/ns/team-a/v1/list","new":"http://localhost:3333/ns/team-a/v1/new","self":"http://localhost:3333/ns/team-a"}}` {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "team-a")
+	}
+	serve(t, handler, http.MethodPut, "/ns/team-a", "", nil, http.StatusOK)
+	serve(t, handler, http.MethodPut, "/ns/team.a", "", nil, http.StatusBadRequest)
+
+	r := serve(t, handler, http.MethodPost, "/ns/team-a/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hello", "Hello World", nil, http.StatusCreated).Body.String()
+	if !strings.Contains(r, `"path":"http://localhost:3333/ns/team-a/v1/hello"`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "http://localhost:3333/ns/team-a/v1/hello")
+	}
+
+	if r := serve(t, handler, http.MethodGet, "/ns/team-a/v1/hello", "", nil, http.StatusOK).Body.String(); r != "Hello World" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello World")
+	}
+	serve(t, handler, http.MethodGet, "/v1/hello", "", nil, http.StatusNotFound)
+	serve(t, handler, http.MethodGet, "/ns/team-a/static/charsets", "", nil, http.StatusNotFound)
+
+	// the namespace is created on the first mocked request
+	serve(t, handler, http.MethodGet, "/ns/team-b/v1/list", "", nil, http.StatusNotFound)
+	created, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodPost, "/ns/team-b/v1/new?status=204&contentType=text/plain&charset=UTF-8", "", nil, http.StatusCreated).Body.Bytes())
+	if r := serve(t, handler, http.MethodGet, "/ns/team-b/v1/list?token="+created["token"].(string), "", nil, http.StatusOK).Body.String(); !strings.Contains(r, `"status":204`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "204")
+	}
+	if r := serve(t, handler, http.MethodGet, "/ns/team-b/v1/list", "", nil, http.StatusOK).Body.String(); r != "[]" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "[]")
+	}
+
+	if r := serve(t, handler, http.MethodGet, "/ns", "", nil, http.StatusOK).Body.String(); !strings.Contains(r, `"name":"team-a","reqMax":2,"total":1`) || !strings.Contains(r, `"name":"team-b"`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, []string{"team-a", "team-b"})
+	}
+
+	serve(t, handler, http.MethodDelete, "/ns/team-a", "", nil, http.StatusNoContent)
+	serve(t, handler, http.MethodGet, "/ns/team-a", "", nil, http.StatusNotFound)
+	serve(t, handler, http.MethodDelete, "/ns/team-a", "", nil, http.StatusNotFound)
+	serve(t, handler, http.MethodPost, "/ns/team-b", "", nil, http.StatusNotFound)
+}
+
+// TestNamespaceEndpointsNotEnabled calls HTTPServer.serveNamespace(http.Handler, http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestNamespaceEndpointsNotEnabled(t *testing.T) {
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, &MockerTest{}, *logger, "test").handler()
+
+	serve(t, handler, http.MethodPut, "/ns/team-a", "", nil, http.StatusNotFound)
+
+	if body := serve(t, handler, http.MethodGet, "/ns", "", nil, http.StatusOK).Body.String(); body != "[]" {
+		t.Fatalf(`result: {%v} but expected {%v}`, body, "[]")
+	}
+}
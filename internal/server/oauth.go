@@ -0,0 +1,212 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+var OAUTH_TOKEN_TTL = time.Hour
+var OAUTH_REFRESH_TOKEN_TTL = 24 * time.Hour
+var OAUTH_MAX_TOKENS = 10000
+
+// oauthToken represents a token issued to a client
+type oauthToken struct {
+	clientId  string
+	scope     string
+	expiresAt time.Time
+}
+
+// OAuthTokenResponse represents the response of the token endpoint
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// OAuth represents a local OAuth2 authorization server which issues the bearer tokens
+// checked by the mocked requests with a {bearer} authentication
+type OAuth struct {
+	mu            sync.Mutex
+	clients       map[string]string
+	ttl           time.Duration
+	accessTokens  map[string]oauthToken
+	refreshTokens map[string]oauthToken
+}
+
+// NewOAuth creates and initializes an {OAuth} struct, the tokens are issued to all the clients if {clients} is empty
+func NewOAuth(clients map[string]string, ttl time.Duration) *OAuth {
+	return &OAuth{
+		clients:       clients,
+		ttl:           ttl,
+		accessTokens:  map[string]oauthToken{},
+		refreshTokens: map[string]oauthToken{},
+	}
+}
+
+// ParseOAuthClients parses the OAuth2 clients {value} formatted as {id}:{secret},...
+func ParseOAuthClients(value string) (map[string]string, error) {
+	clients := map[string]string{}
+	for _, entry := range slicesutil.FilterByNonEmpty(strings.Split(value, ",")) {
+		id, secret, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("oauth client {%s} is not valid", id)
+		}
+		clients[id] = secret
+	}
+	return clients, nil
+}
+
+// Issue issues an access token and a refresh token to the {clientId} with the {scope},
+// the access token expires after {ttl} (or the default time-to-live if {ttl} <= 0).
+func (o *OAuth) Issue(clientId, scope string, ttl time.Duration, now time.Time) OAuthTokenResponse {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if ttl <= 0 {
+		ttl = o.ttl
+	}
+
+	response := OAuthTokenResponse{
+		AccessToken:  internal.NewToken(),
+		TokenType:    "Bearer",
+		ExpiresIn:    int(ttl.Seconds()),
+		RefreshToken: internal.NewToken(),
+		Scope:        scope,
+	}
+	putToken(o.accessTokens, response.AccessToken, oauthToken{clientId: clientId, scope: scope, expiresAt: now.Add(ttl)}, now)
+	putToken(o.refreshTokens, response.RefreshToken, oauthToken{clientId: clientId, scope: scope, expiresAt: now.Add(OAUTH_REFRESH_TOKEN_TTL)}, now)
+	return response
+}
+
+// putToken adds the {token} to the {tokens}, the expired tokens at {now} then the ones which expire first
+// are removed to keep at most {OAUTH_MAX_TOKENS} tokens.
+func putToken(tokens map[string]oauthToken, key string, token oauthToken, now time.Time) {
+	if len(tokens) >= OAUTH_MAX_TOKENS {
+		for k, t := range tokens {
+			if !now.Before(t.expiresAt) {
+				delete(tokens, k)
+			}
+		}
+	}
+	for len(tokens) >= OAUTH_MAX_TOKENS {
+		first := ""
+		for k, t := range tokens {
+			if first == "" || t.expiresAt.Before(tokens[first].expiresAt) {
+				first = k
+			}
+		}
+		delete(tokens, first)
+	}
+	tokens[key] = token
+}
+
+// Refresh issues new tokens from the {refreshToken} of the {clientId}, the refresh token can be used only once.
+func (o *OAuth) Refresh(clientId, refreshToken string, ttl time.Duration, now time.Time) (OAuthTokenResponse, error) {
+	o.mu.Lock()
+	token, ok := o.refreshTokens[refreshToken]
+	delete(o.refreshTokens, refreshToken)
+	o.mu.Unlock()
+
+	if !ok || !now.Before(token.expiresAt) || (clientId != "" && clientId != token.clientId) {
+		return OAuthTokenResponse{}, errors.New("refresh token is not valid")
+	}
+	return o.Issue(token.clientId, token.scope, ttl, now), nil
+}
+
+// Validate returns the scope of the access {token} if it is issued and not expired at {now}.
+func (o *OAuth) Validate(token string, now time.Time) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	value, ok := o.accessTokens[token]
+	if !ok || !now.Before(value.expiresAt) {
+		return "", false
+	}
+	return value.scope, true
+}
+
+// Expire removes the tokens expired at {now} and returns the number of removed tokens.
+func (o *OAuth) Expire(now time.Time) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	nb := 0
+	for _, tokens := range []map[string]oauthToken{o.accessTokens, o.refreshTokens} {
+		for key, token := range tokens {
+			if !now.Before(token.expiresAt) {
+				delete(tokens, key)
+				nb = nb + 1
+			}
+		}
+	}
+	return nb
+}
+
+// authenticateClient returns true if the {clientId} and {clientSecret} are registered or if any client is allowed.
+func (o *OAuth) authenticateClient(clientId, clientSecret string) bool {
+	if len(o.clients) == 0 {
+		return true
+	}
+	secret, ok := o.clients[clientId]
+	return ok && equals(secret, clientSecret)
+}
+
+// oauthToken issues the tokens of the {client_credentials} and {refresh_token} grants,
+// the client is authenticated with the basic authentication or the {client_id} and {client_secret} parameters.
+func (s *HTTPServer) oauthToken(w http.ResponseWriter, r *http.Request) {
+	writeOAuthError := func(code string, statusCode int) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(statusCode)
+		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, code)))
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError("invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if !s.oauth.authenticateClient(clientId, clientSecret) {
+		s.logger.Info("oauth client not valid", "uri", r.RequestURI, "clientId", clientId)
+		w.Header().Set("WWW-Authenticate", `Basic realm="mockapic"`)
+		writeOAuthError("invalid_client", http.StatusUnauthorized)
+		return
+	}
+
+	ttl := time.Duration(0)
+	if expiresIn, err := strconv.Atoi(r.PostForm.Get("expires_in")); err == nil && expiresIn > 0 {
+		ttl = time.Duration(expiresIn) * time.Second
+	}
+
+	var response OAuthTokenResponse
+	switch r.PostForm.Get("grant_type") {
+	case "client_credentials":
+		response = s.oauth.Issue(clientId, r.PostForm.Get("scope"), ttl, time.Now())
+	case "refresh_token":
+		var err error
+		if response, err = s.oauth.Refresh(clientId, r.PostForm.Get("refresh_token"), ttl, time.Now()); err != nil {
+			writeOAuthError("invalid_grant", http.StatusBadRequest)
+			return
+		}
+	default:
+		writeOAuthError("unsupported_grant_type", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	s.writeResponse(w, r, response, http.StatusOK)
+}
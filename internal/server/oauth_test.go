@@ -0,0 +1,165 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestOAuth calls OAuth.Issue, OAuth.Refresh, OAuth.Validate and OAuth.Expire,
+// checking for a valid return value.
+func TestOAuth(t *testing.T) {
+	now := time.Now()
+	oauth := NewOAuth(map[string]string{"client": "secret"}, time.Minute)
+
+	if !oauth.authenticateClient("client", "secret") || oauth.authenticateClient("client", "bad") || oauth.authenticateClient("other", "secret") {
+		t.Fatalf(`result: {%v} but expected {%v}`, oauth.clients, "client:secret")
+	}
+
+	issued := oauth.Issue("client", "read write", 0, now)
+	if issued.ExpiresIn != 60 || issued.TokenType != "Bearer" || issued.AccessToken == "" || issued.RefreshToken == "" {
+		t.Fatalf(`result: {%v} but expected {%v}`, issued, 60)
+	}
+	if scope, ok := oauth.Validate(issued.AccessToken, now.Add(59*time.Second)); !ok || scope != "read write" {
+		t.Fatalf(`result: {%v} but expected {%v}`, scope, "read write")
+	}
+	if _, ok := oauth.Validate(issued.AccessToken, now.Add(time.Minute)); ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, false)
+	}
+
+	if _, err := oauth.Refresh("other", issued.RefreshToken, 0, now); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+	// the refresh token is used once
+	issued = oauth.Issue("client", "read", 0, now)
+	refreshed, err := oauth.Refresh("client", issued.RefreshToken, time.Second, now)
+	if err != nil || refreshed.ExpiresIn != 1 || refreshed.Scope != "read" {
+		t.Fatalf(`result: {%v} but expected {%v}`, refreshed, 1)
+	}
+	if _, err := oauth.Refresh("client", issued.RefreshToken, 0, now); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+
+	if nb := oauth.Expire(now.Add(time.Minute)); nb != 3 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 3)
+	}
+
+	// the tokens which expire first are removed over the max limit
+	defer func(max int) { OAUTH_MAX_TOKENS = max }(OAUTH_MAX_TOKENS)
+	OAUTH_MAX_TOKENS = 2
+	oauth = NewOAuth(nil, time.Minute)
+	first := oauth.Issue("client", "", time.Second, now)
+	second := oauth.Issue("client", "", time.Hour, now)
+	third := oauth.Issue("client", "", time.Minute, now)
+	if len(oauth.accessTokens) != 2 || len(oauth.refreshTokens) != 2 {
+		t.Fatalf(`result: {%v} {%v} but expected {%v}`, len(oauth.accessTokens), len(oauth.refreshTokens), 2)
+	}
+	if _, ok := oauth.Validate(first.AccessToken, now); ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, false)
+	}
+	for _, issued := range []OAuthTokenResponse{second, third} {
+		if _, ok := oauth.Validate(issued.AccessToken, now); !ok {
+			t.Fatalf(`result: {%v} but expected {%v}`, ok, true)
+		}
+	}
+}
+
+// TestOAuthTokenEndpoint calls HTTPServer.oauthToken(http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestOAuthTokenEndpoint(t *testing.T) {
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, &MockerTest{}, *logger, "test").
+		WithOAuth(NewOAuth(map[string]string{"client": "secret"}, time.Hour)).
+		handler()
+
+	call := func(form url.Values, basic bool, expectedStatusCode int) string {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost:3333/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if basic {
+			req.SetBasicAuth("client", "secret")
+		}
+		return serveRequest(t, handler, req, expectedStatusCode).Body.String()
+	}
+
+	call(url.Values{"grant_type": {"client_credentials"}}, false, http.StatusUnauthorized)
+	call(url.Values{"grant_type": {"client_credentials"}, "client_id": {"client"}, "client_secret": {"bad"}}, false, http.StatusUnauthorized)
+	if r := call(url.Values{"grant_type": {"password"}}, true, http.StatusBadRequest); r != `{"error": "unsupported_grant_type"}` {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "unsupported_grant_type")
+	}
+
+	issued, _ := jsonsutil.Unmarshal[OAuthTokenResponse]([]byte(call(url.Values{"grant_type": {"client_credentials"}, "client_id": {"client"}, "client_secret": {"secret"}, "expires_in": {"120"}}, false, http.StatusOK)))
+	if issued.AccessToken == "" || issued.ExpiresIn != 120 {
+		t.Fatalf(`result: {%v} but expected {%v}`, issued, 120)
+	}
+
+	refreshed, _ := jsonsutil.Unmarshal[OAuthTokenResponse]([]byte(call(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {issued.RefreshToken}}, true, http.StatusOK)))
+	if refreshed.AccessToken == "" || refreshed.AccessToken == issued.AccessToken || refreshed.ExpiresIn != 3600 {
+		t.Fatalf(`result: {%v} but expected {%v}`, refreshed, 3600)
+	}
+	if r := call(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {issued.RefreshToken}}, true, http.StatusBadRequest); r != `{"error": "invalid_grant"}` {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "invalid_grant")
+	}
+}
+
+// TestAuthenticate calls HTTPServer.authenticate on the mocked requests with a simulated authentication,
+// checking for a valid return value.
+func TestAuthenticate(t *testing.T) {
+	mock := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, mock, *logger, "test")
+	handler := s.handler()
+
+	call := func(method, url, body string, headers map[string]string, expectedStatusCode int) *httptest.ResponseRecorder {
+		header := http.Header{}
+		for key, value := range headers {
+			header.Set(key, value)
+		}
+		return serve(t, handler, method, url, body, header, expectedStatusCode)
+	}
+
+	call(http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/bearer&auth=bearer&authScope=read&authValues=static-token", "bearer", nil, http.StatusCreated)
+	call(http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/key&auth=apiKey&authValues=key-1", "key", nil, http.StatusCreated)
+	call(http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hmac&auth=hmac&authSecret=secret", "hmac", nil, http.StatusCreated)
+	call(http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&auth=digest", "", nil, http.StatusInternalServerError)
+
+	// bearer
+	if w := call(http.MethodGet, "/v1/bearer", "", nil, http.StatusUnauthorized); w.Header().Get("WWW-Authenticate") != `Bearer realm="mockapic"` {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Header(), "WWW-Authenticate")
+	}
+	if w := call(http.MethodGet, "/v1/bearer", "", map[string]string{"Authorization": "Bearer unknown"}, http.StatusUnauthorized); !strings.Contains(w.Header().Get("WWW-Authenticate"), `error="invalid_token"`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Header(), "invalid_token")
+	}
+	call(http.MethodGet, "/v1/bearer", "", map[string]string{"Authorization": "Bearer static-token"}, http.StatusOK)
+	write := s.oauth.Issue("client", "write", 0, time.Now())
+	call(http.MethodGet, "/v1/bearer", "", map[string]string{"Authorization": "Bearer " + write.AccessToken}, http.StatusForbidden)
+	read := s.oauth.Issue("client", "read write", 0, time.Now())
+	if w := call(http.MethodGet, "/v1/bearer", "", map[string]string{"Authorization": "Bearer " + read.AccessToken}, http.StatusOK); w.Body.String() != "bearer" {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Body.String(), "bearer")
+	}
+	expired := s.oauth.Issue("client", "read", 0, time.Now().Add(-2*OAUTH_TOKEN_TTL))
+	call(http.MethodGet, "/v1/bearer", "", map[string]string{"Authorization": "Bearer " + expired.AccessToken}, http.StatusUnauthorized)
+
+	// api key
+	call(http.MethodGet, "/v1/key", "", nil, http.StatusUnauthorized)
+	call(http.MethodGet, "/v1/key", "", map[string]string{"X-API-Key": "key-2"}, http.StatusForbidden)
+	call(http.MethodGet, "/v1/key", "", map[string]string{"X-API-Key": "key-1"}, http.StatusOK)
+
+	// hmac, the signed body is still available
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`{"id":1}`))
+	call(http.MethodPost, "/v1/hmac", `{"id":1}`, map[string]string{"X-Signature": "bad"}, http.StatusForbidden)
+	call(http.MethodPost, "/v1/hmac", `{"id":1}`, map[string]string{"X-Signature": hex.EncodeToString(mac.Sum(nil))}, http.StatusOK)
+
+	// the failures are recorded in the journal
+	entries := s.journal.Entries()
+	if len(entries) == 0 || entries[0].Status != http.StatusOK {
+		t.Fatalf(`result: {%v} but expected {%v}`, entries, http.StatusOK)
+	}
+}
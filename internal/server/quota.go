@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+var QUOTA_WINDOW = time.Hour
+
+// clientQuota represents the mocked requests created by a client
+type clientQuota struct {
+	creations []time.Time
+	mockIds   map[string][]string
+}
+
+// Quotas limits the number of stored mocked requests and of creations per hour of each client (remote address),
+// the quotas are kept in memory and reset on restart ({remote-addr.json} counts only the total of the creations).
+type Quotas struct {
+	mu         sync.Mutex
+	maxMocks   int
+	maxPerHour int
+	clients    map[string]*clientQuota
+}
+
+// NewQuotas creates and initializes a {Quotas} struct, a limit < 1 is unlimited
+func NewQuotas(maxMocks, maxPerHour int) *Quotas {
+	return &Quotas{
+		maxMocks:   maxMocks,
+		maxPerHour: maxPerHour,
+		clients:    map[string]*clientQuota{},
+	}
+}
+
+// Allow checks that the {client} can create {nb} mocked requests in the {space} at {now},
+// the {stored} function returns the identifiers of the mocked requests still stored in the {space}.
+// It returns the delay before retrying if a quota is exceeded.
+func (q *Quotas) Allow(client, space string, nb int, stored func() []string, now time.Time) (time.Duration, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	c := q.client(client, now)
+
+	if q.maxPerHour > 0 && len(c.creations)+nb > q.maxPerHour {
+		retryAfter := QUOTA_WINDOW
+		if len(c.creations) > 0 {
+			retryAfter = c.creations[0].Add(QUOTA_WINDOW).Sub(now)
+		}
+		return retryAfter, fmt.Errorf("quota of {%d} mocked requests per hour exceeded for {%s}", q.maxPerHour, client)
+	}
+
+	if q.maxMocks > 0 {
+		if len(c.mockIds[space]) > 0 {
+			ids := stored()
+			c.mockIds[space] = slicesutil.FilterT(c.mockIds[space], func(id string) bool { return slicesutil.Exist(ids, id) })
+		}
+		if len(c.mockIds[space])+nb > q.maxMocks {
+			return QUOTA_WINDOW, fmt.Errorf("quota of {%d} mocked requests exceeded for {%s}", q.maxMocks, client)
+		}
+	}
+	return 0, nil
+}
+
+// Record counts the mocked request {mockId} created by the {client} in the {space} at {now}.
+func (q *Quotas) Record(client, space, mockId string, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	c := q.client(client, now)
+	c.creations = append(c.creations, now)
+	c.mockIds[space] = append(c.mockIds[space], mockId)
+}
+
+// Expire removes the clients without creation in the window at {now} and without mocked request still stored,
+// the {stored} function returns the identifiers of the mocked requests still stored in a space.
+// It returns the number of removed clients.
+func (q *Quotas) Expire(now time.Time, stored func(space string) []string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	nb := 0
+	for client := range q.clients {
+		c := q.client(client, now)
+		for space, mockIds := range c.mockIds {
+			ids := stored(space)
+			if c.mockIds[space] = slicesutil.FilterT(mockIds, func(id string) bool { return slicesutil.Exist(ids, id) }); len(c.mockIds[space]) == 0 {
+				delete(c.mockIds, space)
+			}
+		}
+		if len(c.creations) == 0 && len(c.mockIds) == 0 {
+			delete(q.clients, client)
+			nb = nb + 1
+		}
+	}
+	return nb
+}
+
+// client returns the quota of the {client} without the creations out of the window at {now}.
+func (q *Quotas) client(client string, now time.Time) *clientQuota {
+	c, ok := q.clients[client]
+	if !ok {
+		c = &clientQuota{mockIds: map[string][]string{}}
+		q.clients[client] = c
+	}
+	c.creations = slicesutil.FilterT(c.creations, func(t time.Time) bool { return now.Sub(t) < QUOTA_WINDOW })
+	return c
+}
+
+// allowQuota checks the quotas of the client of the request {r} and writes a 429 response if they are exceeded.
+func (s *HTTPServer) allowQuota(w http.ResponseWriter, r *http.Request, sc scope, nb int) bool {
+	if s.quotas == nil {
+		return true
+	}
+
+	retryAfter, err := s.quotas.Allow(s.findRemoteAddr(r.RemoteAddr), sc.key(), nb, func() []string {
+		return storedIds(sc.target())
+	}, time.Now())
+
+	if err != nil {
+		s.logger.Info("quota exceeded", "uri", r.RequestURI, "error", err.Error())
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		writeError(w, err, http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+// recordQuota counts the mocked request {mockId} created by the client of the request {r}.
+func (s *HTTPServer) recordQuota(r *http.Request, sc scope, mockId string) {
+	if s.quotas != nil {
+		s.quotas.Record(s.findRemoteAddr(r.RemoteAddr), sc.key(), mockId, time.Now())
+	}
+}
+
+// storedIds returns the identifiers of the mocked requests stored in the {space}.
+func storedIds(space *mockSpace) []string {
+	values, _ := space.mocker.List()
+	return slicesutil.TransformT(values, func(mrl internal.MockedRequestLight) (*string, error) { return &mrl.Id, nil })
+}
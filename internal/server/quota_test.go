@@ -0,0 +1,91 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestQuotas calls Quotas.Allow and Quotas.Record,
+// checking for a valid return value.
+func TestQuotas(t *testing.T) {
+	now := time.Now()
+	stored := func() []string { return []string{"id-2"} }
+
+	quotas := NewQuotas(-1, 2)
+	quotas.Record("127.0.0.1", "", "id-1", now.Add(-30*time.Minute))
+	quotas.Record("127.0.0.1", "", "id-2", now)
+	if retryAfter, err := quotas.Allow("127.0.0.1", "", 1, stored, now); err == nil || retryAfter != 30*time.Minute {
+		t.Fatalf(`result: {%v} but expected {%v}`, retryAfter, 30*time.Minute)
+	}
+	if _, err := quotas.Allow("127.0.0.2", "", 1, stored, now); err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+	}
+	if _, err := quotas.Allow("127.0.0.1", "", 1, stored, now.Add(31*time.Minute)); err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+	}
+
+	quotas = NewQuotas(2, -1)
+	quotas.Record("127.0.0.1", "", "id-1", now)
+	quotas.Record("127.0.0.1", "", "id-2", now)
+	// {id-1} is not stored anymore
+	if _, err := quotas.Allow("127.0.0.1", "", 1, stored, now); err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+	}
+	if _, err := quotas.Allow("127.0.0.1", "", 2, stored, now); err == nil || err.Error() != "quota of {2} mocked requests exceeded for {127.0.0.1}" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "quota of {2} mocked requests exceeded for {127.0.0.1}")
+	}
+	if _, err := quotas.Allow("127.0.0.1", "ns-a", 2, stored, now); err != nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, nil)
+	}
+}
+
+// TestQuotasExpire calls Quotas.Expire,
+// checking for a valid return value.
+func TestQuotasExpire(t *testing.T) {
+	now := time.Now()
+	stored := map[string][]string{"": {"id-2"}}
+
+	quotas := NewQuotas(2, 2)
+	quotas.Record("127.0.0.1", "", "id-1", now.Add(-2*time.Hour))
+	quotas.Record("127.0.0.2", "", "id-2", now.Add(-2*time.Hour))
+	quotas.Record("127.0.0.3", "session", "id-3", now)
+
+	// {127.0.0.1} has no creation in the window and no stored mocked request
+	if nb := quotas.Expire(now, func(space string) []string { return stored[space] }); nb != 1 || len(quotas.clients) != 2 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 1)
+	}
+	if _, ok := quotas.clients["127.0.0.1"]; ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, false)
+	}
+
+	// {id-2} is removed and the creation of {127.0.0.3} is out of the window
+	if nb := quotas.Expire(now.Add(2*time.Hour), func(space string) []string { return nil }); nb != 2 || len(quotas.clients) != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 2)
+	}
+}
+
+// TestAddNewMockEndpointWithQuotas calls HTTPServer.addNewMock(http.ResponseWriter, *http.Request) with quotas,
+// checking for a valid return value.
+func TestAddNewMockEndpointWithQuotas(t *testing.T) {
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test").
+		WithQuotas(NewQuotas(2, -1)).
+		handler()
+
+	call := func(remoteAddr string, expectedStatusCode int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8", strings.NewReader("Hello World"))
+		req.RemoteAddr = remoteAddr
+		return serveRequest(t, handler, req, expectedStatusCode)
+	}
+
+	call("127.0.0.1:1234", http.StatusCreated)
+	call("127.0.0.1:1235", http.StatusCreated)
+	if w := call("127.0.0.1:1236", http.StatusTooManyRequests); w.Header().Get("Retry-After") != "3600" {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Header().Get("Retry-After"), "3600")
+	}
+	call("127.0.0.2:1234", http.StatusCreated)
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/mockapic/internal"
+	"github.com/joakim-ribier/mockapic/pkg"
+)
+
+// tokenBucket represents the calls available on a rate limit, refilled continuously over its period
+type tokenBucket struct {
+	limit     float64
+	tokens    float64
+	rate      float64
+	updatedAt time.Time
+}
+
+// RateLimitResult represents the state of a token bucket after a call
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimiter represents the token buckets of the rate limited mocked requests
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// NewRateLimiter creates and initializes a {RateLimiter} struct
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{buckets: map[string]*tokenBucket{}}
+}
+
+// Take consumes a token of the bucket {key} which allows {limit} calls per {period} at {now}.
+func (l *RateLimiter) Take(key string, limit int, period time.Duration, now time.Time) RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rate := float64(limit) / period.Seconds()
+	bucket, ok := l.buckets[key]
+	if !ok || bucket.limit != float64(limit) || bucket.rate != rate {
+		bucket = &tokenBucket{limit: float64(limit), tokens: float64(limit), rate: rate, updatedAt: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = bucket.refill(now)
+	bucket.updatedAt = now
+
+	result := RateLimitResult{Allowed: bucket.tokens >= 1}
+	if result.Allowed {
+		bucket.tokens = bucket.tokens - 1
+	} else {
+		result.RetryAfter = seconds((1 - bucket.tokens) / rate)
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = seconds((bucket.limit - bucket.tokens) / rate)
+	return result
+}
+
+// refill returns the tokens available in the bucket at {now}.
+func (b *tokenBucket) refill(now time.Time) float64 {
+	return math.Min(b.limit, b.tokens+now.Sub(b.updatedAt).Seconds()*b.rate)
+}
+
+// Expire removes the buckets refilled at {now} and returns the number of removed buckets.
+func (l *RateLimiter) Expire(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	nb := 0
+	for key, bucket := range l.buckets {
+		if bucket.refill(now) >= bucket.limit {
+			delete(l.buckets, key)
+			nb = nb + 1
+		}
+	}
+	return nb
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
+
+// ParseRateLimits parses the rate limits {value} of the path prefixes formatted as {prefix}={limit}/{period},...
+func ParseRateLimits(value string) (map[string]*internal.MockedRequestRateLimit, error) {
+	rateLimits := map[string]*internal.MockedRequestRateLimit{}
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		prefix, limit, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("rate limit {%s} is not valid", entry)
+		}
+		rateLimit, err := internal.ParseRateLimit(limit)
+		if err != nil {
+			return nil, err
+		}
+		rateLimits[prefix] = rateLimit
+	}
+	return rateLimits, nil
+}
+
+// findRateLimit returns the rate limit of the {mock} or of the longest path prefix matching the request {r}.
+func (s *HTTPServer) findRateLimit(r *http.Request, sc scope, mock *internal.MockedRequest) (string, *internal.MockedRequestRateLimit) {
+	if mock.RateLimit != nil {
+		return sc.key() + "/mock/" + mock.Id, mock.RateLimit
+	}
+
+	decodedURI, _ := url.QueryUnescape(strings.TrimPrefix(r.URL.Path, "/v1"))
+	prefixes := make([]string, 0, len(s.rateLimits))
+	for prefix := range s.rateLimits {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(decodedURI, prefix) {
+			return sc.key() + "/prefix" + prefix, s.rateLimits[prefix]
+		}
+	}
+	return "", nil
+}
+
+// rateLimit writes the {X-RateLimit-*} headers if the {mock} is rate limited,
+// and returns the mocked request to respond with if the limit is exceeded.
+func (s *HTTPServer) rateLimit(w http.ResponseWriter, r *http.Request, sc scope, mock *internal.MockedRequest) *internal.MockedRequest {
+	key, rateLimit := s.findRateLimit(r, sc, mock)
+	if rateLimit == nil {
+		return nil
+	}
+
+	period, err := rateLimit.Interval()
+	if err != nil {
+		s.logger.Error(err, "error to parse rate limit", "uri", r.RequestURI, "mockId", mock.Id)
+		return nil
+	}
+
+	result := s.rateLimiter.Take(key, rateLimit.Limit, period, time.Now())
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rateLimit.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.Reset.Seconds()))))
+	if result.Allowed {
+		return nil
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+	if rateLimit.MockId != "" {
+		for _, space := range sc.spaces() {
+			if limited, err := space.mocker.Get(rateLimit.MockId); err == nil {
+				return limited
+			}
+		}
+		s.logger.Info("rate limit mock not found", "uri", r.RequestURI, "mockId", rateLimit.MockId)
+	}
+
+	limited := internal.NewMockedRequestFromHttpCode(http.StatusTooManyRequests, pkg.HTTP_CODES[http.StatusTooManyRequests])
+	return &limited
+}
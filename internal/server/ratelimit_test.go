@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestRateLimiter calls RateLimiter.Take and RateLimiter.Expire,
+// checking for a valid return value.
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter()
+
+	for i := 1; i >= 0; i-- {
+		if result := limiter.Take("key", 2, time.Minute, now); !result.Allowed || result.Remaining != i {
+			t.Fatalf(`result: {%v} but expected {%v}`, result, i)
+		}
+	}
+
+	result := limiter.Take("key", 2, time.Minute, now.Add(10*time.Second))
+	if result.Allowed || result.RetryAfter != 20*time.Second || result.Reset != 50*time.Second {
+		t.Fatalf(`result: {%v} but expected {%v}`, result, "retry after 20s")
+	}
+
+	if result := limiter.Take("key", 2, time.Minute, now.Add(30*time.Second)); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, result, 0)
+	}
+
+	if nb := limiter.Expire(now.Add(time.Minute)); nb != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 0)
+	}
+	if nb := limiter.Expire(now.Add(2 * time.Minute)); nb != 1 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 1)
+	}
+}
+
+// TestParseRateLimits calls ParseRateLimits,
+// checking for a valid return value.
+func TestParseRateLimits(t *testing.T) {
+	rateLimits, err := ParseRateLimits("/api=100/1m, /auth=5/1s")
+	if err != nil || len(rateLimits) != 2 || rateLimits["/auth"].Limit != 5 || rateLimits["/api"].Period != "1m" {
+		t.Fatalf(`result: {%v} but expected {%v}`, rateLimits, "/api=100/1m, /auth=5/1s")
+	}
+
+	if _, err := ParseRateLimits("/api=0/1m"); err == nil || err.Error() != "rate limit {0/1m} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "rate limit {0/1m} is not valid")
+	}
+}
+
+// TestGetMockedRequestEndpointWithRateLimit calls HTTPServer.getMockedRequest(http.ResponseWriter, *http.Request) with rate limits,
+// checking for a valid return value.
+func TestGetMockedRequestEndpointWithRateLimit(t *testing.T) {
+	rateLimits, _ := ParseRateLimits("/api=1/1h")
+	handler := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test").
+		WithRateLimits(rateLimits).
+		handler()
+
+	limited, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodPost, "/v1/new?status=503&contentType=text/plain&charset=UTF-8", "Slow down", nil, http.StatusCreated).Body.Bytes())
+
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/upstream&rateLimit=2/1h", "Hello World", nil, http.StatusCreated)
+	serve(t, handler, http.MethodPost, fmt.Sprintf("/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/custom&rateLimit=1/1h&rateLimitMockId=%v", limited["id"]), "Hello World", nil, http.StatusCreated)
+	serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/api/users", "[]", nil, http.StatusCreated)
+
+	w := serve(t, handler, http.MethodGet, "/v1/upstream", "", nil, http.StatusOK)
+	if w.Header().Get("X-RateLimit-Limit") != "2" || w.Header().Get("X-RateLimit-Remaining") != "1" || w.Header().Get("X-RateLimit-Reset") != "1800" {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Header(), "X-RateLimit-* headers")
+	}
+	serve(t, handler, http.MethodGet, "/v1/upstream", "", nil, http.StatusOK)
+	w = serve(t, handler, http.MethodGet, "/v1/upstream", "", nil, http.StatusTooManyRequests)
+	if w.Header().Get("Retry-After") != "1800" || w.Header().Get("X-RateLimit-Remaining") != "0" || w.Body.String() != "Too Many Requests" {
+		t.Fatalf(`result: {%v} but expected {%v}`, w.Header(), "Retry-After: 1800")
+	}
+
+	serve(t, handler, http.MethodGet, "/v1/custom", "", nil, http.StatusOK)
+	if data := serve(t, handler, http.MethodGet, "/v1/custom", "", nil, http.StatusServiceUnavailable).Body.String(); data != "Slow down" {
+		t.Fatalf(`result: {%v} but expected {%v}`, data, "Slow down")
+	}
+
+	serve(t, handler, http.MethodGet, "/v1/api/users", "", nil, http.StatusOK)
+	serve(t, handler, http.MethodGet, "/v1/api/users", "", nil, http.StatusTooManyRequests)
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/logsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+var WATCH_INTERVAL = 2 * time.Second
+
+// ReloadStatus represents the result of the last loads of the predefined mocked requests
+type ReloadStatus struct {
+	Sources  []string `json:"sources"`
+	Files    []string `json:"files"`
+	Total    int      `json:"total"`
+	LoadedAt string   `json:"loadedAt,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	FailedAt string   `json:"failedAt,omitempty"`
+}
+
+// Reloader loads the predefined mocked requests of the {sources} files and directories
+// and swaps them with their paths when the files change
+type Reloader struct {
+	mu          sync.RWMutex
+	sources     []string
+	home        string
+	mock        internal.Mock
+	routes      *Routes
+	fingerprint string
+	status      ReloadStatus
+	logger      logsutil.Logger
+}
+
+// NewReloader creates and initializes a {Reloader} struct
+func NewReloader(sources []string, home string, mock internal.Mock, routes *Routes, logger logsutil.Logger) *Reloader {
+	return &Reloader{
+		sources: sources,
+		home:    home,
+		mock:    mock,
+		routes:  routes,
+		status:  ReloadStatus{Sources: sources, Files: []string{}},
+		logger:  logger.Namespace("reloader"),
+	}
+}
+
+// Reload loads the predefined mocked requests and swaps them,
+// the previous ones are kept if the files cannot be loaded.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fingerprint = internal.PredefinedFingerprint(r.sources)
+	values, files, err := internal.LoadPredefined(r.sources)
+	if err == nil {
+		err = checkBodyFiles(r.home, values)
+	}
+	if err != nil {
+		r.logger.Error(err, "error to load predefined requests", "sources", r.sources)
+		r.status.Error, r.status.FailedAt = err.Error(), time.Now().Format(time.RFC3339)
+		return err
+	}
+
+	previous := r.mock.SetPredefined(values)
+	r.routes.Replace(predefinedRoutes(previous), predefinedRoutes(values))
+
+	r.status = ReloadStatus{Sources: r.sources, Files: files, Total: len(values), LoadedAt: time.Now().Format(time.RFC3339)}
+	r.logger.Info("predefined requests loaded", "files", files, "total", len(values))
+	return nil
+}
+
+// Watch reloads the predefined mocked requests each time their files change, checked every {interval}.
+func (r *Reloader) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		r.mu.RLock()
+		changed := r.fingerprint != internal.PredefinedFingerprint(r.sources)
+		r.mu.RUnlock()
+
+		if changed {
+			r.Reload()
+		}
+	}
+}
+
+// Status returns the result of the last loads.
+func (r *Reloader) Status() ReloadStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.status
+}
+
+// checkBodyFiles checks that the body files of the predefined mocked requests {values} exist in the {home} directory.
+func checkBodyFiles(home string, values []internal.PredefinedMockedRequest) error {
+	for _, value := range values {
+		if value.BodyFile == "" {
+			continue
+		}
+		if _, err := internal.BodyFilePath(home, value.BodyFile); err != nil {
+			return fmt.Errorf("mock {%s} in {%s}: %v", value.Id, value.Source, err)
+		}
+	}
+	return nil
+}
+
+func predefinedRoutes(values []internal.PredefinedMockedRequest) map[string][]string {
+	routes := map[string][]string{}
+	for _, value := range values {
+		if value.Path != "" {
+			routes["/v1"+value.Path] = append(routes["/v1"+value.Path], value.Id)
+		}
+	}
+	return routes
+}
+
+func (s *HTTPServer) getPredefinedStatus(w http.ResponseWriter, r *http.Request) {
+	if s.reloader == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.writeResponse(w, r, s.reloader.Status(), http.StatusOK)
+}
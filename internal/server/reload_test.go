@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestReloader calls Reloader.Reload and the {/v1/predefined} endpoint,
+// checking for a valid return value.
+func TestReloader(t *testing.T) {
+	directory := t.TempDir()
+	file := filepath.Join(directory, "mockapic.json")
+	os.WriteFile(file, []byte(`[{"id":"id-1","status":200,"contentType":"text/plain","charset":"UTF-8","path":"/one","body":"one"}]`), 0644)
+
+	mock := internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger)
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), directory, -1, mock, *logger, "test")
+	reloader := NewReloader([]string{file}, filepath.Join(directory, "files"), mock, s.Routes, *logger)
+	handler := s.WithReloader(reloader).handler()
+
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if r := serve(t, handler, http.MethodGet, "/v1/one", "", nil, http.StatusOK).Body.String(); r != "one" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "one")
+	}
+
+	// the paths are swapped with the predefined requests
+	os.WriteFile(file, []byte(`[{"id":"id-2","status":200,"contentType":"text/plain","charset":"UTF-8","path":"/two","body":"two"}]`), 0644)
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err.Error())
+	}
+	serve(t, handler, http.MethodGet, "/v1/one", "", nil, http.StatusNotFound)
+	if r := serve(t, handler, http.MethodGet, "/v1/two", "", nil, http.StatusOK).Body.String(); r != "two" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "two")
+	}
+
+	// the previous predefined requests are kept if the file cannot be parsed
+	os.WriteFile(file, []byte(`[{"id":"id-3"`), 0644)
+	if err := reloader.Reload(); err == nil {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "error")
+	}
+	serve(t, handler, http.MethodGet, "/v1/two", "", nil, http.StatusOK)
+
+	status, _ := jsonsutil.Unmarshal[ReloadStatus](serve(t, handler, http.MethodGet, "/v1/predefined", "", nil, http.StatusOK).Body.Bytes())
+	if status.Total != 1 || status.LoadedAt == "" || !strings.Contains(status.Error, "cannot be parsed") || status.FailedAt == "" {
+		t.Fatalf(`result: {%v} but expected {%v}`, status, "parse error")
+	}
+
+	// the body files are checked on load
+	os.WriteFile(file, []byte(`[{"id":"id-4","status":200,"path":"/four","bodyFile":"four.json"}]`), 0644)
+	if err := reloader.Reload(); err == nil || err.Error() != "mock {id-4} in {"+file+"}: bodyFile {four.json} does not exist" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "bodyFile {four.json} does not exist")
+	}
+	os.MkdirAll(filepath.Join(directory, "files"), os.ModePerm)
+	os.WriteFile(filepath.Join(directory, "files", "four.json"), []byte(`{"id":4}`), 0644)
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if r := serve(t, handler, http.MethodGet, "/v1/four", "", nil, http.StatusOK).Body.String(); r != `{"id":4}` {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, `{"id":4}`)
+	}
+}
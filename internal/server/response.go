@@ -1,7 +1,12 @@
 package server
 
 import (
+	"io"
+	"mime"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/joakim-ribier/go-utils/pkg/genericsutil"
@@ -13,6 +18,7 @@ import (
 type Response struct {
 	ResponseWriter http.ResponseWriter
 	DelayMax       time.Duration
+	Home           string
 }
 
 // NewResponse creates and initializes a {Response} struct
@@ -25,23 +31,39 @@ func NewResponse(responseWriter http.ResponseWriter, delayMax string) Response {
 	}
 }
 
+// WithHome sets the directory of the body files.
+func (r Response) WithHome(home string) Response {
+	r.Home = home
+	return r
+}
+
 // Write writes the http response using the provided {mock} value
-// and delays the response {delay} parameter is setted
-func (r Response) Write(mock internal.MockedRequest, delay string) {
-	var duration time.Duration = 0
-	if parse, err := time.ParseDuration(delay); err == nil {
-		duration = genericsutil.OrElse(
-			parse, func() bool { return parse <= r.DelayMax }, r.DelayMax)
+// and delays the response {delay} parameter is setted,
+// it returns the written status code ({500} if the body file cannot be read).
+func (r Response) Write(mock internal.MockedRequest, delay string) int {
+	if duration := r.Delay(delay); duration > 0 {
+		time.Sleep(duration)
 	}
 
-	if duration > 0 {
-		time.Sleep(duration)
+	if mock.BodyFile != "" {
+		return r.writeBodyFile(mock)
 	}
 
 	r.
 		writeContentType(mock).
 		writeHeaders(mock).
 		writeBody(mock)
+	return mock.Status
+}
+
+// Delay returns the duration of the {delay} parameter limited to {DelayMax}, zero if it is not valid.
+func (r Response) Delay(delay string) time.Duration {
+	var duration time.Duration = 0
+	if parse, err := time.ParseDuration(delay); err == nil {
+		duration = genericsutil.OrElse(
+			parse, func() bool { return parse <= r.DelayMax }, r.DelayMax)
+	}
+	return duration
 }
 
 func (r Response) writeContentType(mock internal.MockedRequest) Response {
@@ -56,13 +78,87 @@ func (r Response) writeHeaders(mock internal.MockedRequest) Response {
 	for key, value := range mock.Headers {
 		r.ResponseWriter.Header().Set(key, value)
 	}
+	r.writePush(mock)
+	for key := range mock.Trailers {
+		r.ResponseWriter.Header().Add("Trailer", key)
+	}
 	r.ResponseWriter.WriteHeader(mock.Status)
 	return r
 }
 
+// writePush pushes the {push} resources of the {mock} if the connection supports the server push (HTTP/2),
+// and adds their {Link: <path>; rel=preload} hints.
+func (r Response) writePush(mock internal.MockedRequest) {
+	pusher, ok := r.ResponseWriter.(http.Pusher)
+	for _, path := range mock.Push {
+		r.ResponseWriter.Header().Add("Link", "<"+path+">; rel=preload")
+		if ok {
+			if err := pusher.Push(path, nil); err != nil {
+				// the client has disabled the server push
+				ok = false
+			}
+		}
+	}
+}
+
 func (r Response) writeBody(mock internal.MockedRequest) Response {
 	if len(mock.Body64) > 0 {
 		r.ResponseWriter.Write(mock.Body64)
 	}
+	return r.writeTrailers(mock)
+}
+
+// writeTrailers writes the trailers of the {mock} after the body.
+func (r Response) writeTrailers(mock internal.MockedRequest) Response {
+	for key, value := range mock.Trailers {
+		r.ResponseWriter.Header().Set(key, value)
+	}
 	return r
 }
+
+// writeBodyFile streams the body file of the {mock} from the disk,
+// the content length and the content type (if not specified) are inferred from the file.
+func (r Response) writeBodyFile(mock internal.MockedRequest) int {
+	path, err := internal.BodyFilePath(r.Home, mock.BodyFile)
+	if err != nil {
+		writeError(r.ResponseWriter, err, http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		writeError(r.ResponseWriter, err, http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		writeError(r.ResponseWriter, err, http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+
+	if mock.ContentType != "" {
+		r.writeContentType(mock)
+	} else {
+		r.ResponseWriter.Header().Set("Content-Type", detectContentType(file))
+	}
+	r.ResponseWriter.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+
+	r.writeHeaders(mock)
+	io.Copy(r.ResponseWriter, file)
+	r.writeTrailers(mock)
+	return mock.Status
+}
+
+// detectContentType returns the content type from the extension of the {file} or from its first bytes.
+func detectContentType(file *os.File) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(file.Name())); contentType != "" {
+		return contentType
+	}
+
+	data := make([]byte, 512)
+	n, _ := io.ReadFull(file, data)
+	file.Seek(0, io.SeekStart)
+	return http.DetectContentType(data[:n])
+}
@@ -1,7 +1,11 @@
 package server
 
 import (
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
@@ -87,3 +91,49 @@ func TestWriteWithMaxDelay(t *testing.T) {
 		t.Fatalf(`result: {%v} but expected {%v}`, withTime.TimeInMillis, "1s max")
 	}
 }
+
+// TestWriteWithBodyFile calls Response.Write(internal.Mock, string),
+// checking for a valid return value.
+func TestWriteWithBodyFile(t *testing.T) {
+	directory := t.TempDir()
+	os.MkdirAll(filepath.Join(directory, "files"), os.ModePerm)
+	os.WriteFile(filepath.Join(directory, "files", "user.json"), []byte(`{"id":1}`), 0644)
+	os.WriteFile(filepath.Join(directory, "files", "image"), []byte("\x89PNG\r\n\x1a\n"), 0644)
+
+	write := func(mocked internal.MockedRequest) *http.Response {
+		w := httptest.NewRecorder()
+		NewResponse(w, "60s").WithHome(directory).Write(mocked, "")
+		return w.Result()
+	}
+
+	mocked := internal.MockedRequest{
+		MockedRequestLight: internal.MockedRequestLight{
+			MockedRequestHeader: internal.MockedRequestHeader{Status: 200, BodyFile: "files/user.json"},
+		},
+	}
+	res := write(mocked)
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != 200 || string(body) != `{"id":1}` ||
+		res.Header.Get("Content-Type") != "application/json" || res.Header.Get("Content-Length") != "8" {
+		t.Fatalf(`result: {%v} but expected {%v}`, res.Header, "application/json")
+	}
+
+	// the content type is sniffed if the file has no extension
+	mocked.BodyFile = "files/image"
+	if res := write(mocked); res.Header.Get("Content-Type") != "image/png" || res.Header.Get("Content-Length") != "8" {
+		t.Fatalf(`result: {%v} but expected {%v}`, res.Header, "image/png")
+	}
+
+	// the content type is not inferred if it is specified
+	mocked.ContentType, mocked.Charset = "application/octet-stream", "UTF-8"
+	if res := write(mocked); res.Header.Get("Content-Type") != "application/octet-stream; charset=UTF-8" {
+		t.Fatalf(`result: {%v} but expected {%v}`, res.Header, "application/octet-stream")
+	}
+
+	for _, bodyFile := range []string{"files/does-not-exist", "../user.json", "/etc/hosts"} {
+		mocked.BodyFile = bodyFile
+		if res := write(mocked); res.StatusCode != http.StatusInternalServerError {
+			t.Fatalf(`result: {%v} but expected {%v}`, res.StatusCode, http.StatusInternalServerError)
+		}
+	}
+}
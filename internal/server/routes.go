@@ -0,0 +1,86 @@
+package server
+
+import (
+	"slices"
+	"sync"
+)
+
+// Routes represents the paths of the mocked requests (e.g. {/v1/my-path}) and their identifiers,
+// a path can be bound to several mocked requests (e.g. {GET} and {POST} on the same path),
+// it is safe for concurrent use by the handlers
+type Routes struct {
+	mu     sync.RWMutex
+	values map[string][]string
+}
+
+// NewRoutes creates and initializes a {Routes} struct
+func NewRoutes() *Routes {
+	return &Routes{values: map[string][]string{}}
+}
+
+// Get returns the identifiers of the mocked requests bound to the {path}, the most recent first.
+func (r *Routes) Get(path string) ([]string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mockIds, ok := r.values[path]
+	return slices.Clone(mockIds), ok
+}
+
+// Set binds the {path} to the mocked request {mockId} in first position.
+func (r *Routes) Set(path, mockId string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bind(path, []string{mockId})
+}
+
+// Delete unbinds the {path} from the mocked request {mockId}.
+func (r *Routes) Delete(path, mockId string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.unbind(path, mockId)
+}
+
+// Len returns the number of bound paths.
+func (r *Routes) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.values)
+}
+
+// Replace unbinds the {previous} paths from their mocked requests and binds the {next} paths at once.
+func (r *Routes) Replace(previous, next map[string][]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for path, mockIds := range previous {
+		for _, mockId := range mockIds {
+			r.unbind(path, mockId)
+		}
+	}
+	for path, mockIds := range next {
+		r.bind(path, mockIds)
+	}
+}
+
+func (r *Routes) bind(path string, mockIds []string) {
+	values := slices.DeleteFunc(slices.Clone(r.values[path]), func(id string) bool { return slices.Contains(mockIds, id) })
+	r.values[path] = append(slices.Clone(mockIds), values...)
+}
+
+func (r *Routes) unbind(path, mockId string) bool {
+	values := r.values[path]
+	i := slices.Index(values, mockId)
+	if i < 0 {
+		return false
+	}
+	if len(values) == 1 {
+		delete(r.values, path)
+	} else {
+		r.values[path] = slices.Delete(slices.Clone(values), i, i+1)
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestRoutes calls Routes.Set, Routes.Get and Routes.Delete,
+// checking for a valid return value.
+func TestRoutes(t *testing.T) {
+	routes := NewRoutes()
+	routes.Set("/v1/hello", "id-1")
+	routes.Set("/v1/hello", "id-2")
+
+	if mockIds, ok := routes.Get("/v1/hello"); !ok || strings.Join(mockIds, ",") != "id-2,id-1" {
+		t.Fatalf(`result: {%v} but expected {%v}`, mockIds, "id-2,id-1")
+	}
+
+	// the path is still bound to another mocked request
+	if !routes.Delete("/v1/hello", "id-1") || routes.Len() != 1 {
+		t.Fatalf(`result: {%v} but expected {%v}`, routes.Len(), 1)
+	}
+	if routes.Delete("/v1/hello", "id-1") {
+		t.Fatalf(`result: {%v} but expected {%v}`, true, false)
+	}
+	if !routes.Delete("/v1/hello", "id-2") || routes.Len() != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, routes.Len(), 0)
+	}
+}
+
+// TestRoutesReplace calls Routes.Replace,
+// checking for a valid return value.
+func TestRoutesReplace(t *testing.T) {
+	routes := NewRoutes()
+	routes.Set("/v1/hello", "id-1")
+	routes.Replace(nil, map[string][]string{"/v1/hello": {"predefined-1", "predefined-2"}})
+	routes.Replace(map[string][]string{"/v1/hello": {"predefined-1", "predefined-2"}}, map[string][]string{"/v1/hello": {"predefined-3"}})
+
+	if mockIds, _ := routes.Get("/v1/hello"); strings.Join(mockIds, ",") != "predefined-3,id-1" {
+		t.Fatalf(`result: {%v} but expected {%v}`, mockIds, "predefined-3,id-1")
+	}
+}
+
+// TestSamePathDifferentMethods calls the {/v1/import/wiremock} and {/v1/{path}} endpoints
+// with two mappings on the same path but with different methods,
+// checking for a valid return value.
+func TestSamePathDifferentMethods(t *testing.T) {
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), t.TempDir(), -1, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test")
+	handler := s.handler()
+
+	serve(t, handler, http.MethodPost, "/v1/import/wiremock", `{"mappings": [
+		{"request": {"method": "GET", "urlPath": "/users"}, "response": {"status": 200, "body": "list"}},
+		{"request": {"method": "POST", "urlPath": "/users"}, "response": {"status": 201, "body": "created"}}
+	]}`, nil, http.StatusCreated)
+
+	if body := serve(t, handler, http.MethodGet, "/v1/users", "", nil, http.StatusOK).Body.String(); body != "list" {
+		t.Fatalf(`result: {%v} but expected {%v}`, body, "list")
+	}
+	if body := serve(t, handler, http.MethodPost, "/v1/users", "", nil, http.StatusCreated).Body.String(); body != "created" {
+		t.Fatalf(`result: {%v} but expected {%v}`, body, "created")
+	}
+	serve(t, handler, http.MethodDelete, "/v1/users", "", nil, http.StatusNotFound)
+}
+
+// TestRoutesAfterClean calls HTTPServer.clean(scope),
+// checking for a valid return value.
+func TestRoutesAfterClean(t *testing.T) {
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), t.TempDir(), 2, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test")
+	handler := s.handler()
+
+	for i := 0; i < 10; i++ {
+		serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/same", strconv.Itoa(i), nil, http.StatusCreated)
+	}
+
+	// the paths of the removed mocked requests are unbound
+	if ids, _ := s.Routes.Get("/v1/same"); len(ids) != 2 {
+		t.Fatalf(`result: {%v} but expected {%v}`, ids, 2)
+	}
+}
+
+// TestRoutesConcurrently calls Routes.Set, Routes.Get and Routes.Delete from concurrent goroutines,
+// checking for a valid return value (run with the -race flag).
+func TestRoutesConcurrently(t *testing.T) {
+	routes := NewRoutes()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path := "/v1/path-" + strconv.Itoa(i%5)
+			for j := 0; j < 100; j++ {
+				routes.Set(path, strconv.Itoa(i))
+				routes.Get(path)
+				routes.Delete(path, strconv.Itoa(j))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if routes.Len() > 5 {
+		t.Fatalf(`result: {%v} but expected {%v}`, routes.Len(), "<= 5")
+	}
+}
+
+// TestHandlerConcurrently calls the {/v1/new} and {/v1/{path}} endpoints from concurrent goroutines,
+// checking for a valid return value (run with the -race flag).
+func TestHandlerConcurrently(t *testing.T) {
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), t.TempDir(), 100, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test").
+		WithNamespaces(NewNamespaces(internal.NewMemoryStorage(*logger), 100, *logger))
+	handler := s.handler()
+
+	var wg sync.WaitGroup
+	errors := make(chan string, 400)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prefix := []string{"", "/ns/team-a", "/session/test-" + strconv.Itoa(i%2)}[i%3]
+			for j := 0; j < 10; j++ {
+				path := fmt.Sprintf("/path-%d-%d", i, j)
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, prefix+"/v1/new?status=200&contentType=text/plain&charset=UTF-8&path="+path, nil))
+				if w.Code != http.StatusCreated {
+					errors <- fmt.Sprintf("[POST] %s => %d", prefix+path, w.Code)
+				}
+
+				w = httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, prefix+"/v1"+path, nil))
+				if w.Code != http.StatusOK {
+					errors <- fmt.Sprintf("[GET] %s => %d", prefix+path, w.Code)
+				}
+
+				w = httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, prefix+"/v1/list", nil))
+			}
+		}(i)
+	}
+	go s.expire(time.Now())
+	wg.Wait()
+	close(errors)
+
+	for err := range errors {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "no error")
+	}
+}
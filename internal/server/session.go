@@ -0,0 +1,324 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joakim-ribier/go-utils/pkg/logsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+const SESSION_HEADER = "X-Mockapic-Session"
+
+var SESSION_TTL = 30 * time.Minute
+
+type sessionContextKey struct{}
+
+// mockSpace represents a set of mocked requests, their paths and the journal of their calls
+type mockSpace struct {
+	mocker  internal.Mocker
+	routes  *Routes
+	journal *Journal
+}
+
+// Session represents the mocked requests visible only to the requests carrying its identifier
+type Session struct {
+	mockSpace
+	Id        string
+	ttl       time.Duration
+	expiresAt atomic.Int64
+}
+
+// touch extends the expiration of the session.
+func (s *Session) touch(now time.Time) {
+	s.expiresAt.Store(now.Add(s.ttl).UnixNano())
+}
+
+// ExpiresAt returns the expiration time of the session.
+func (s *Session) ExpiresAt() time.Time {
+	return time.Unix(0, s.expiresAt.Load())
+}
+
+// Sessions represents the opened sessions of a namespace
+type Sessions struct {
+	mu     sync.Mutex
+	logger logsutil.Logger
+	values map[string]*Session
+}
+
+// NewSessions creates and initializes a {Sessions} struct
+func NewSessions(logger logsutil.Logger) *Sessions {
+	return &Sessions{
+		logger: logger,
+		values: map[string]*Session{},
+	}
+}
+
+// Open returns the session {id} and creates it if it does not exist,
+// each call extends the expiration of the session by its time-to-live.
+func (s *Sessions) Open(id string, ttl time.Duration) (*Session, error) {
+	if id == "" {
+		id = uuid.NewString()
+	}
+	if !NAMESPACE_NAME.MatchString(id) {
+		return nil, fmt.Errorf("session {%s} is not valid", id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.values[id]
+	if !ok {
+		session = s.newSession(id, ttl)
+		s.values[id] = session
+	}
+	session.touch(time.Now())
+
+	return session, nil
+}
+
+// Find returns the session {id} if it is opened and extends its expiration,
+// otherwise an empty session which is not kept (the session is opened only when mocked requests are created on it).
+func (s *Sessions) Find(id string, ttl time.Duration) (*Session, error) {
+	if !NAMESPACE_NAME.MatchString(id) {
+		return nil, fmt.Errorf("session {%s} is not valid", id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.values[id]; ok {
+		session.touch(time.Now())
+		return session, nil
+	}
+	return s.newSession(id, ttl), nil
+}
+
+func (s *Sessions) newSession(id string, ttl time.Duration) *Session {
+	return &Session{
+		mockSpace: mockSpace{
+			mocker:  internal.NewMockWithStore(internal.NewMemoryStore(), nil, s.logger),
+			routes:  NewRoutes(),
+			journal: NewJournal(JOURNAL_MAX_ENTRIES),
+		},
+		Id:  id,
+		ttl: ttl,
+	}
+}
+
+// Get returns the session {id} if it is opened.
+func (s *Sessions) Get(id string) (*Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.values[id]
+	return session, ok
+}
+
+// Close removes the session {id} and all its mocked requests.
+func (s *Sessions) Close(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.values[id]
+	delete(s.values, id)
+	return ok
+}
+
+// List returns the opened sessions.
+func (s *Sessions) List() []*Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := make([]*Session, 0, len(s.values))
+	for _, session := range s.values {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Expire closes the sessions expired at {now} and returns the number of closed sessions.
+func (s *Sessions) Expire(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nb := 0
+	for id, session := range s.values {
+		if session.ExpiresAt().Before(now) {
+			delete(s.values, id)
+			nb = nb + 1
+		}
+	}
+	return nb
+}
+
+// withSessionId returns a shallow copy of the request {r} bound to the session {id} by the URL prefix
+func withSessionId(r *http.Request, id string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), sessionContextKey{}, id))
+}
+
+// scope represents the mocked requests visible by a request
+type scope struct {
+	ns      *Namespace
+	session *Session
+}
+
+// spaces returns the session mocked requests first then the namespace ones.
+func (s scope) spaces() []*mockSpace {
+	if s.session != nil {
+		return []*mockSpace{&s.session.mockSpace, &s.ns.mockSpace}
+	}
+	return []*mockSpace{&s.ns.mockSpace}
+}
+
+// target returns the mocked requests where the new ones are created.
+func (s scope) target() *mockSpace {
+	return s.spaces()[0]
+}
+
+// key returns the identifier of the mocked requests where the new ones are created.
+func (s scope) key() string {
+	if s.session != nil {
+		return s.ns.Name + "/session/" + s.session.Id
+	}
+	return s.ns.Name
+}
+
+// scope returns the mocked requests visible by the request {r}:
+// the ones of its namespace and of its session (URL prefix or {X-Mockapic-Session} header),
+// the session is not opened if it does not exist.
+func (s *HTTPServer) scope(r *http.Request) (scope, error) {
+	return s.findScope(r, false)
+}
+
+// openScope returns the mocked requests visible by the request {r} and opens its session if it does not exist.
+func (s *HTTPServer) openScope(r *http.Request) (scope, error) {
+	return s.findScope(r, true)
+}
+
+func (s *HTTPServer) findScope(r *http.Request, open bool) (scope, error) {
+	ns := s.namespace(r)
+	sc := scope{ns: ns}
+
+	id, _ := r.Context().Value(sessionContextKey{}).(string)
+	if id == "" {
+		id = r.Header.Get(SESSION_HEADER)
+	}
+
+	if id != "" {
+		find := ns.sessions.Find
+		if open {
+			find = ns.sessions.Open
+		}
+		session, err := find(id, s.sessionTTL)
+		if err != nil {
+			return sc, err
+		}
+		sc.session = session
+	}
+	return sc, nil
+}
+
+// serveSession dispatches the {/session/{id}/v1/...} requests to the {/v1/...} handlers bound to the session.
+func (s *HTTPServer) serveSession(handler http.Handler, w http.ResponseWriter, r *http.Request) {
+	id, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/session/"), "/")
+	if !strings.HasPrefix(sub, "v1/") || !NAMESPACE_NAME.MatchString(id) {
+		s.logRequest(r)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	r = withSessionId(r, id)
+	u := *r.URL
+	u.Path = "/" + sub
+	u.RawPath = ""
+	r.URL = &u
+
+	handler.ServeHTTP(w, r)
+}
+
+type SessionWithLinks struct {
+	Id                  string            `json:"id"`
+	ExpiresAt           string            `json:"expiresAt"`
+	TotalNumberRequests int               `json:"total"`
+	Links               map[string]string `json:"_links,omitempty"`
+}
+
+func (s *HTTPServer) toSessionWithLinks(r *http.Request, ns *Namespace, session *Session) SessionWithLinks {
+	total := 0
+	if values, err := session.mocker.List(); err == nil {
+		total = len(values)
+	}
+
+	baseURL := s.getProtocol(r) + "://" + r.Host + ns.prefix()
+	return SessionWithLinks{
+		Id:                  session.Id,
+		ExpiresAt:           session.ExpiresAt().Format(time.RFC3339),
+		TotalNumberRequests: total,
+		Links: map[string]string{
+			"self":   baseURL + "/v1/sessions/" + session.Id,
+			"prefix": baseURL + "/session/" + session.Id + "/v1/",
+		},
+	}
+}
+
+func (s *HTTPServer) openSession(w http.ResponseWriter, r *http.Request) {
+	ttl := s.sessionTTL
+	if value := r.URL.Query().Get("ttl"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, fmt.Errorf("ttl {%s} is not valid", value), http.StatusBadRequest)
+			return
+		}
+		ttl = parsed
+	}
+
+	ns := s.namespace(r)
+	session, err := ns.sessions.Open(r.URL.Query().Get("id"), ttl)
+	if err != nil {
+		s.logger.Error(err, "error to open session", "uri", r.RequestURI)
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	s.writeResponse(w, r, s.toSessionWithLinks(r, ns, session), http.StatusCreated)
+}
+
+func (s *HTTPServer) getOrCloseSession(w http.ResponseWriter, r *http.Request) {
+	ns := s.namespace(r)
+	id := strings.TrimPrefix(r.URL.Path, "/v1/sessions/")
+
+	if r.Method == http.MethodDelete {
+		if !ns.sessions.Close(id) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	session, ok := ns.sessions.Get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.writeResponse(w, r, s.toSessionWithLinks(r, ns, session), http.StatusOK)
+}
+
+// prefix returns the URL prefix of the namespace and of the session bound to the request {r}.
+func (s *HTTPServer) prefix(r *http.Request) string {
+	prefix := s.namespace(r).prefix()
+	if name := boundNamespace(r); name != "" && name == s.namespace(r).Name {
+		prefix = ""
+	}
+	if id, _ := r.Context().Value(sessionContextKey{}).(string); id != "" {
+		prefix = prefix + "/session/" + id
+	}
+	return prefix
+}
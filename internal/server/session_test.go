@@ -0,0 +1,133 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"github.com/joakim-ribier/mockapic/internal"
+)
+
+// TestSessions calls Sessions.Open, Sessions.Find, Sessions.Get, Sessions.Close and Sessions.Expire,
+// checking for a valid return value.
+func TestSessions(t *testing.T) {
+	sessions := NewSessions(*logger)
+
+	if _, err := sessions.Open("bad/id", time.Minute); err == nil || err.Error() != "session {bad/id} is not valid" {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "session {bad/id} is not valid")
+	}
+
+	generated, err := sessions.Open("", time.Minute)
+	if err != nil || generated.Id == "" {
+		t.Fatalf(`result: {%v} but expected {%v}`, generated, "generated id")
+	}
+
+	session, _ := sessions.Open("my-session", time.Hour)
+	if again, _ := sessions.Open("my-session", time.Hour); again != session {
+		t.Fatalf(`result: {%v} but expected {%v}`, again, session)
+	}
+
+	if nb := sessions.Expire(time.Now().Add(2 * time.Minute)); nb != 1 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 1)
+	}
+	if _, ok := sessions.Get(generated.Id); ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, false)
+	}
+
+	// the unknown session is not kept
+	if found, err := sessions.Find("unknown", time.Hour); err != nil || found.Id != "unknown" {
+		t.Fatalf(`result: {%v} but expected {%v}`, found, "unknown")
+	}
+	if _, ok := sessions.Get("unknown"); ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, false)
+	}
+	if found, _ := sessions.Find("my-session", time.Hour); found != session {
+		t.Fatalf(`result: {%v} but expected {%v}`, found, session)
+	}
+
+	if !sessions.Close("my-session") || sessions.Close("my-session") {
+		t.Fatalf(`result: {%v} but expected {%v}`, true, false)
+	}
+}
+
+// TestSessionEndpoints calls HTTPServer.serveSession(http.Handler, http.ResponseWriter, *http.Request),
+// checking for a valid return value.
+func TestSessionEndpoints(t *testing.T) {
+	s := NewHTTPServer("{port}", NewSSL(false, "", "", ""), workingDirectory, -1, internal.NewMockWithStore(internal.NewMemoryStore(), nil, *logger), *logger, "test").
+		WithNamespaces(NewNamespaces(internal.NewFileStorage(t.TempDir(), *logger), -1, *logger))
+	handler := s.handler()
+
+	// shared mocked request
+	shared, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hello", "Hello shared", nil, http.StatusCreated).Body.Bytes())
+
+	// the same path is overridden only in the session {test-1}
+	overridden, _ := jsonsutil.Unmarshal[map[string]any](serve(t, handler, http.MethodPost, "/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hello", "Hello test-1", http.Header{SESSION_HEADER: {"test-1"}}, http.StatusCreated).Body.Bytes())
+	r := serve(t, handler, http.MethodPost, "/session/test-2/v1/new?status=200&contentType=text/plain&charset=UTF-8&path=/hello", "Hello test-2", nil, http.StatusCreated).Body.String()
+	if !strings.Contains(r, `"path":"http://localhost:3333/session/test-2/v1/hello"`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "http://localhost:3333/session/test-2/v1/hello")
+	}
+
+	if r := serve(t, handler, http.MethodGet, "/v1/hello", "", nil, http.StatusOK).Body.String(); r != "Hello shared" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello shared")
+	}
+	if r := serve(t, handler, http.MethodGet, "/v1/hello", "", http.Header{SESSION_HEADER: {"test-1"}}, http.StatusOK).Body.String(); r != "Hello test-1" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello test-1")
+	}
+	if r := serve(t, handler, http.MethodGet, "/session/test-2/v1/hello", "", nil, http.StatusOK).Body.String(); r != "Hello test-2" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello test-2")
+	}
+	if r := serve(t, handler, http.MethodGet, "/v1/hello", "", http.Header{SESSION_HEADER: {"test-3"}}, http.StatusOK).Body.String(); r != "Hello shared" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello shared")
+	}
+	serve(t, handler, http.MethodGet, "/v1/hello", "", http.Header{SESSION_HEADER: {"bad.id"}}, http.StatusBadRequest)
+
+	// the list of the session contains its mocked requests and the shared ones
+	if r := serve(t, handler, http.MethodGet, "/v1/list?token="+shared["token"].(string)+","+overridden["token"].(string), "", http.Header{SESSION_HEADER: {"test-1"}}, http.StatusOK).Body.String(); strings.Count(r, `"id"`) != 2 {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, 2)
+	}
+
+	// the journal of the session contains only its requests
+	journal, _ := jsonsutil.Unmarshal[[]JournalEntry](serve(t, handler, http.MethodGet, "/v1/journal", "", http.Header{SESSION_HEADER: {"test-1"}}, http.StatusOK).Body.Bytes())
+	if len(journal) != 1 || !strings.HasSuffix(journal[0].URI, "/v1/hello") || journal[0].Status != 200 {
+		t.Fatalf(`result: {%v} but expected {%v}`, journal, "/v1/hello")
+	}
+	serve(t, handler, http.MethodDelete, "/v1/journal", "", http.Header{SESSION_HEADER: {"test-1"}}, http.StatusNoContent)
+	if r := serve(t, handler, http.MethodGet, "/v1/journal", "", http.Header{SESSION_HEADER: {"test-1"}}, http.StatusOK).Body.String(); r != "[]" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "[]")
+	}
+
+	// open and close a session on a namespace
+	serve(t, handler, http.MethodPost, "/v1/sessions?id=bad.id", "", nil, http.StatusBadRequest)
+	r = serve(t, handler, http.MethodPost, "/ns/team-a/v1/sessions?ttl=1m", "", nil, http.StatusCreated).Body.String()
+	session, _ := jsonsutil.Unmarshal[SessionWithLinks]([]byte(r))
+	if session.Links["prefix"] != "http://localhost:3333/ns/team-a/session/"+session.Id+"/v1/" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "prefix link")
+	}
+	serve(t, handler, http.MethodPost, "/ns/team-a/session/"+session.Id+"/v1/new?status=204&contentType=text/plain&charset=UTF-8&path=/empty", "", nil, http.StatusCreated)
+	serve(t, handler, http.MethodGet, "/ns/team-a/session/"+session.Id+"/v1/empty", "", nil, http.StatusNoContent)
+	serve(t, handler, http.MethodGet, "/ns/team-a/v1/empty", "", nil, http.StatusNotFound)
+	if r := serve(t, handler, http.MethodGet, "/ns/team-a/v1/sessions/"+session.Id, "", nil, http.StatusOK).Body.String(); !strings.Contains(r, `"total":1`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, 1)
+	}
+	serve(t, handler, http.MethodDelete, "/ns/team-a/v1/sessions/"+session.Id, "", nil, http.StatusNoContent)
+	serve(t, handler, http.MethodGet, "/ns/team-a/v1/sessions/"+session.Id, "", nil, http.StatusNotFound)
+	serve(t, handler, http.MethodDelete, "/ns/team-a/v1/sessions/"+session.Id, "", nil, http.StatusNotFound)
+
+	serve(t, handler, http.MethodPost, "/v1/sessions?ttl=bad", "", nil, http.StatusBadRequest)
+	serve(t, handler, http.MethodGet, "/session/test-1/static/charsets", "", nil, http.StatusNotFound)
+
+	// the session {test-3} is not opened by the calls to the mocked requests
+	if _, ok := s.sessions.Get("test-3"); ok {
+		t.Fatalf(`result: {%v} but expected {%v}`, ok, false)
+	}
+
+	// the sessions expire without activity
+	if nb := s.expire(time.Now().Add(SESSION_TTL + time.Minute)); nb != 2 {
+		t.Fatalf(`result: {%v} but expected {%v}`, nb, 2)
+	}
+	if r := serve(t, handler, http.MethodGet, "/v1/hello", "", http.Header{SESSION_HEADER: {"test-1"}}, http.StatusOK).Body.String(); r != "Hello shared" {
+		t.Fatalf(`result: {%v} but expected {%v}`, r, "Hello shared")
+	}
+}
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joakim-ribier/go-utils/pkg/logsutil"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	STORAGE_FILE   = "file"
+	STORAGE_MEMORY = "memory"
+	STORAGE_BOLT   = "bolt"
+)
+
+// STORAGES represents the backends available to store the mocked requests
+var STORAGES = []string{STORAGE_FILE, STORAGE_MEMORY, STORAGE_BOLT}
+
+// Storage opens the mocked requests of the namespaces
+type Storage interface {
+	Open(namespace string) (Mocker, error)
+	Drop(namespace string) error
+	Namespaces() ([]string, error)
+}
+
+// FileStorage stores the mocked requests of each namespace in a sub directory
+type FileStorage struct {
+	workingDirectory string
+	eviction         string
+	logger           logsutil.Logger
+}
+
+// NewFileStorage creates and initializes a {FileStorage} struct
+func NewFileStorage(workingDirectory string, logger logsutil.Logger) FileStorage {
+	return FileStorage{
+		workingDirectory: workingDirectory,
+		eviction:         EVICTION_CREATED,
+		logger:           logger.Namespace("storage"),
+	}
+}
+
+// WithEviction sets the eviction {policy} of the namespaces mocked requests.
+func (s FileStorage) WithEviction(policy string) FileStorage {
+	s.eviction = policy
+	return s
+}
+
+// Open creates the {namespace} directory if it does not exist and returns its mocker.
+func (s FileStorage) Open(namespace string) (Mocker, error) {
+	directory := s.workingDirectory + "/" + namespace
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		s.logger.Error(err, "error to create directory", "namespace", namespace, "workingDirectory", s.workingDirectory)
+		return nil, err
+	}
+	return NewMock(directory, nil, s.logger).WithEviction(s.eviction), nil
+}
+
+// Drop removes the {namespace} directory and all its mocked requests.
+func (s FileStorage) Drop(namespace string) error {
+	if err := os.RemoveAll(s.workingDirectory + "/" + namespace); err != nil {
+		s.logger.Error(err, "error to remove directory", "namespace", namespace, "workingDirectory", s.workingDirectory)
+		return err
+	}
+	return nil
+}
+
+// Namespaces returns the name of the existing namespaces.
+func (s FileStorage) Namespaces() ([]string, error) {
+	fileEntries, err := os.ReadDir(s.workingDirectory + "/")
+	if err != nil {
+		s.logger.Error(err, "error to read directory", "workingDirectory", s.workingDirectory)
+		return nil, err
+	}
+
+	namespaces := []string{}
+	for _, e := range fileEntries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			namespaces = append(namespaces, e.Name())
+		}
+	}
+	return namespaces, nil
+}
+
+// MemoryStorage stores the mocked requests of each namespace in memory
+type MemoryStorage struct {
+	mu       *sync.Mutex
+	eviction string
+	logger   logsutil.Logger
+	stores   map[string]MemoryStore
+}
+
+// NewMemoryStorage creates and initializes a {MemoryStorage} struct
+func NewMemoryStorage(logger logsutil.Logger) MemoryStorage {
+	return MemoryStorage{
+		mu:       &sync.Mutex{},
+		eviction: EVICTION_CREATED,
+		logger:   logger.Namespace("storage"),
+		stores:   map[string]MemoryStore{},
+	}
+}
+
+// WithEviction sets the eviction {policy} of the namespaces mocked requests.
+func (s MemoryStorage) WithEviction(policy string) MemoryStorage {
+	s.eviction = policy
+	return s
+}
+
+// Open creates the {namespace} store if it does not exist and returns its mocker.
+func (s MemoryStorage) Open(namespace string) (Mocker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, ok := s.stores[namespace]
+	if !ok {
+		store = NewMemoryStore()
+		s.stores[namespace] = store
+	}
+	return NewMockWithStore(store, nil, s.logger).WithEviction(s.eviction), nil
+}
+
+// Drop removes the {namespace} store and all its mocked requests.
+func (s MemoryStorage) Drop(namespace string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.stores, namespace)
+	return nil
+}
+
+// Namespaces returns the name of the existing namespaces.
+func (s MemoryStorage) Namespaces() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	namespaces := make([]string, 0, len(s.stores))
+	for namespace := range s.stores {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// BOLT_NAMESPACE_PREFIX represents the prefix of the namespaces buckets in the database
+var BOLT_NAMESPACE_PREFIX = "ns/"
+
+// BoltStorage stores the mocked requests of each namespace in a bucket of an embedded key/value database file
+type BoltStorage struct {
+	db       *bbolt.DB
+	eviction string
+	logger   logsutil.Logger
+}
+
+// NewBoltStorage creates and initializes a {BoltStorage} struct
+func NewBoltStorage(db *bbolt.DB, logger logsutil.Logger) BoltStorage {
+	return BoltStorage{
+		db:       db,
+		eviction: EVICTION_CREATED,
+		logger:   logger.Namespace("storage"),
+	}
+}
+
+// WithEviction sets the eviction {policy} of the namespaces mocked requests.
+func (s BoltStorage) WithEviction(policy string) BoltStorage {
+	s.eviction = policy
+	return s
+}
+
+// Open creates the {namespace} bucket if it does not exist and returns its mocker.
+func (s BoltStorage) Open(namespace string) (Mocker, error) {
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(BOLT_NAMESPACE_PREFIX + namespace))
+		return err
+	})
+	if err != nil {
+		s.logger.Error(err, "error to create bucket", "namespace", namespace, "db", s.db.Path())
+		return nil, err
+	}
+	return NewMockWithStore(NewBoltStore(s.db, BOLT_NAMESPACE_PREFIX+namespace), nil, s.logger).WithEviction(s.eviction), nil
+}
+
+// Drop removes the {namespace} bucket and all its mocked requests.
+func (s BoltStorage) Drop(namespace string) error {
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		if tx.Bucket([]byte(BOLT_NAMESPACE_PREFIX+namespace)) == nil {
+			return nil
+		}
+		return tx.DeleteBucket([]byte(BOLT_NAMESPACE_PREFIX + namespace))
+	})
+	if err != nil {
+		s.logger.Error(err, "error to delete bucket", "namespace", namespace, "db", s.db.Path())
+	}
+	return err
+}
+
+// Namespaces returns the name of the existing namespaces.
+func (s BoltStorage) Namespaces() ([]string, error) {
+	namespaces := []string{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+			if strings.HasPrefix(string(name), BOLT_NAMESPACE_PREFIX) {
+				namespaces = append(namespaces, strings.TrimPrefix(string(name), BOLT_NAMESPACE_PREFIX))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Error(err, "error to list buckets", "db", s.db.Path())
+	}
+	return namespaces, err
+}
+
+// OpenStorage creates the {kind} backend in the {workingDirectory} and returns
+// the store of the default mocked requests and the storage of the namespaces.
+func OpenStorage(kind, workingDirectory, eviction string, logger logsutil.Logger) (Store, Storage, error) {
+	switch kind {
+	case STORAGE_FILE:
+		requestsDir := workingDirectory + "/requests"
+		if err := os.MkdirAll(requestsDir, os.ModePerm); err != nil {
+			return nil, nil, err
+		}
+		return NewFileStore(requestsDir), NewFileStorage(requestsDir, logger).WithEviction(eviction), nil
+	case STORAGE_MEMORY:
+		return NewMemoryStore(), NewMemoryStorage(logger).WithEviction(eviction), nil
+	case STORAGE_BOLT:
+		db, err := bbolt.Open(workingDirectory+"/mockapic.db", 0600, &bbolt.Options{Timeout: time.Second})
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewBoltStore(db, "requests"), NewBoltStorage(db, logger).WithEviction(eviction), nil
+	default:
+		return nil, nil, fmt.Errorf("storage {%s} does not exist", kind)
+	}
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/joakim-ribier/go-utils/pkg/slicesutil"
+)
+
+// TestFileStorage calls FileStorage.Open, FileStorage.Namespaces and FileStorage.Drop,
+// checking for a valid return value.
+func TestFileStorage(t *testing.T) {
+	directory := t.TempDir()
+	storage := NewFileStorage(directory, *logger)
+
+	mocker, err := storage.Open("team-a")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mock, err := mocker.New(map[string][]string{
+		"status":      {"200"},
+		"contentType": {"text/plain"},
+		"charset":     {"UTF-8"},
+	}, []byte("Hello World"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := os.Stat(directory + "/team-a/" + mock.Id + ".json"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the default mocker does not list the namespace directories
+	if values, err := NewMock(directory, nil, *logger).List(); err != nil || len(values) != 0 {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, "[]")
+	}
+
+	if err := os.MkdirAll(directory+"/.hidden", os.ModePerm); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	namespaces, err := storage.Namespaces()
+	if err != nil || !slicesutil.Equal(namespaces, []string{"team-a"}) {
+		t.Fatalf(`result: {%v} but expected {%v}`, namespaces, []string{"team-a"})
+	}
+
+	if err := storage.Drop("team-a"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, err := os.Stat(directory + "/team-a"); !os.IsNotExist(err) {
+		t.Fatalf(`result: {%v} but expected {%v}`, err, "no such file or directory")
+	}
+}
+
+// TestFileStorageWithBadWorkingDir calls FileStorage.Namespaces,
+// checking for a valid return value.
+func TestFileStorageWithBadWorkingDir(t *testing.T) {
+	if r, err := NewFileStorage("wrong-directory", *logger).Namespaces(); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, r)
+	}
+}
@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/joakim-ribier/go-utils/pkg/iosutil"
+	"go.etcd.io/bbolt"
+)
+
+// Store persists the serialized mocked requests by identifier
+type Store interface {
+	Read(mockId string) ([]byte, error)
+	Write(mockId string, data []byte) error
+	Delete(mockId string) error
+	Ids() ([]string, error)
+}
+
+// FileStore stores each mocked request in a {id}.json file of the directory
+type FileStore struct {
+	directory string
+}
+
+// NewFileStore creates and initializes a {FileStore} struct
+func NewFileStore(directory string) FileStore {
+	return FileStore{directory: directory}
+}
+
+func (s FileStore) String() string {
+	return s.directory
+}
+
+// Read loads the {mockId}.json file.
+func (s FileStore) Read(mockId string) ([]byte, error) {
+	filename, err := s.filename(mockId)
+	if err != nil {
+		return nil, err
+	}
+	return iosutil.Load(filename)
+}
+
+// Write writes the {mockId}.json file.
+func (s FileStore) Write(mockId string, data []byte) error {
+	filename, err := s.filename(mockId)
+	if err != nil {
+		return err
+	}
+	return iosutil.Write(data, filename)
+}
+
+// Delete removes the {mockId}.json file.
+func (s FileStore) Delete(mockId string) error {
+	filename, err := s.filename(mockId)
+	if err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
+
+// filename returns the {mockId}.json file of the directory,
+// the {mockId} must be a single file name to not read or write outside the directory.
+func (s FileStore) filename(mockId string) (string, error) {
+	if mockId == "" || mockId == "." || mockId == ".." || strings.ContainsAny(mockId, `/\`+"\x00") {
+		return "", fmt.Errorf("mock id {%s} is not valid", mockId)
+	}
+	return s.directory + "/" + mockId + ".json", nil
+}
+
+// Ids returns the identifiers of the *.json files, the sub directories are ignored.
+func (s FileStore) Ids() ([]string, error) {
+	fileEntries, err := os.ReadDir(s.directory + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, e := range fileEntries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	return ids, nil
+}
+
+// MemoryStore stores the mocked requests in memory
+type MemoryStore struct {
+	mu     *sync.RWMutex
+	values map[string][]byte
+}
+
+// NewMemoryStore creates and initializes a {MemoryStore} struct
+func NewMemoryStore() MemoryStore {
+	return MemoryStore{mu: &sync.RWMutex{}, values: map[string][]byte{}}
+}
+
+func (s MemoryStore) String() string {
+	return "memory"
+}
+
+// Read returns the data of the {mockId}.
+func (s MemoryStore) Read(mockId string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.values[mockId]
+	if !ok {
+		return nil, errors.New("mock {" + mockId + "} does not exist")
+	}
+	return data, nil
+}
+
+// Write stores the data of the {mockId}.
+func (s MemoryStore) Write(mockId string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[mockId] = data
+	return nil
+}
+
+// Delete removes the data of the {mockId}.
+func (s MemoryStore) Delete(mockId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[mockId]; !ok {
+		return errors.New("mock {" + mockId + "} does not exist")
+	}
+	delete(s.values, mockId)
+	return nil
+}
+
+// Ids returns the identifiers of the stored mocked requests.
+func (s MemoryStore) Ids() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.values))
+	for id := range s.values {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// BoltStore stores the mocked requests in a bucket of an embedded key/value database file
+type BoltStore struct {
+	db     *bbolt.DB
+	bucket []byte
+}
+
+// NewBoltStore creates and initializes a {BoltStore} struct
+func NewBoltStore(db *bbolt.DB, bucket string) BoltStore {
+	return BoltStore{db: db, bucket: []byte(bucket)}
+}
+
+func (s BoltStore) String() string {
+	return s.db.Path() + "#" + string(s.bucket)
+}
+
+// Close closes the database of the store.
+func (s BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// Read returns the data of the {mockId} from the bucket.
+func (s BoltStore) Read(mockId string) ([]byte, error) {
+	var data []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if bucket := tx.Bucket(s.bucket); bucket != nil {
+			if value := bucket.Get([]byte(mockId)); value != nil {
+				data = append([]byte{}, value...)
+				return nil
+			}
+		}
+		return fmt.Errorf("mock {%s} does not exist", mockId)
+	})
+	return data, err
+}
+
+// Write stores the data of the {mockId} in the bucket, the bucket is created if it does not exist.
+func (s BoltStore) Write(mockId string, data []byte) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(mockId), data)
+	})
+}
+
+// Delete removes the data of the {mockId} from the bucket.
+func (s BoltStore) Delete(mockId string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(s.bucket)
+		if bucket == nil || bucket.Get([]byte(mockId)) == nil {
+			return fmt.Errorf("mock {%s} does not exist", mockId)
+		}
+		return bucket.Delete([]byte(mockId))
+	})
+}
+
+// Ids returns the keys of the bucket.
+func (s BoltStore) Ids() ([]string, error) {
+	ids := []string{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(s.bucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			ids = append(ids, string(k))
+			return nil
+		})
+	})
+	return ids, err
+}
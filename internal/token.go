@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// NewToken generates a random owner token.
+func NewToken() string {
+	value := make([]byte, 16)
+	rand.Read(value)
+	return hex.EncodeToString(value)
+}
+
+// HashToken returns the hash of the owner {token} which is stored with the mocked request.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Owned returns true if the {token} is the owner token of the mocked request,
+// a mocked request without owner token (predefined or created before the tokens) is owned by everyone.
+func (m MockedRequest) Owned(token string) bool {
+	if m.TokenHash == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(m.TokenHash), []byte(HashToken(token))) == 1
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/joakim-ribier/go-utils/pkg/jsonsutil"
+	"gopkg.in/yaml.v3"
+)
+
+var FORMAT_JSON = "json"
+var FORMAT_YAML = "yaml"
+var FORMATS = []string{FORMAT_JSON, FORMAT_YAML}
+
+// FileFormat returns the format of the mocked requests {file} from its extension (*.yaml, *.yml or *.json).
+func FileFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return FORMAT_YAML
+	}
+	return FORMAT_JSON
+}
+
+// UnmarshalPredefined parses the list of predefined mocked requests of the {data} in the {format},
+// a YAML document is converted to JSON first to produce the same values.
+func UnmarshalPredefined(data []byte, format string) ([]PredefinedMockedRequest, error) {
+	if format == FORMAT_YAML {
+		values, err := YAMLToJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		data = values
+	}
+	return jsonsutil.Unmarshal[[]PredefinedMockedRequest](data)
+}
+
+// MarshalPredefined serializes the predefined mocked requests {values} in the {format},
+// the text bodies are exported in the {body} field (a literal block in YAML).
+func MarshalPredefined(values []PredefinedMockedRequest, format string) ([]byte, error) {
+	mocks := make([]PredefinedMockedRequest, 0, len(values))
+	for _, value := range values {
+		if value.Body == "" && len(value.Body64) > 0 && utf8.Valid(value.Body64) {
+			value.Body, value.Body64 = string(value.Body64), nil
+		}
+		mocks = append(mocks, value)
+	}
+
+	data, err := jsonsutil.Marshal(mocks)
+	if err != nil || format != FORMAT_YAML {
+		return data, err
+	}
+	return JSONToYAML(data)
+}
+
+// YAMLToJSON converts the YAML document {data} to JSON, the anchors and the merge keys are resolved.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var value any
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(toJSONValue(value))
+}
+
+// toJSONValue converts the maps with non string keys decoded from YAML to maps supported by JSON.
+func toJSONValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, e := range v {
+			v[key] = toJSONValue(e)
+		}
+		return v
+	case map[any]any:
+		values := map[string]any{}
+		for key, e := range v {
+			values[fmt.Sprint(key)] = toJSONValue(e)
+		}
+		return values
+	case []any:
+		for i, e := range v {
+			v[i] = toJSONValue(e)
+		}
+		return v
+	}
+	return value
+}
+
+// JSONToYAML converts the JSON document {data} to YAML in block style keeping the order of the keys,
+// the multi-line strings are written as literal blocks.
+func JSONToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
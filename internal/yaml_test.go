@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestUnmarshalPredefined calls UnmarshalPredefined([]byte, string),
+// checking for a valid return value.
+func TestUnmarshalPredefined(t *testing.T) {
+	data := `
+# the headers of the first mock are shared with an anchor
+- id: id-1
+  status: 200
+  contentType: application/json
+  charset: UTF-8
+  path: /users
+  headers: &headers
+    Cache-Control: no-cache
+    X-Version: "2"
+  request:
+    method: GET
+  body: |
+    {
+      "name": "Joakim"
+    }
+- id: id-2 # no body
+  status: 204
+  contentType: text/plain
+  charset: UTF-8
+  headers:
+    <<: *headers
+    X-Version: "3"
+  expiresAt: 2026-10-19T10:00:00Z
+`
+
+	values, err := UnmarshalPredefined([]byte(data), FORMAT_YAML)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected, _ := UnmarshalPredefined([]byte(`[
+		{"id":"id-1","status":200,"contentType":"application/json","charset":"UTF-8","path":"/users",
+		 "headers":{"Cache-Control":"no-cache","X-Version":"2"},"request":{"method":"GET"},"body":"{\n  \"name\": \"Joakim\"\n}\n"},
+		{"id":"id-2","status":204,"contentType":"text/plain","charset":"UTF-8",
+		 "headers":{"Cache-Control":"no-cache","X-Version":"3"},"expiresAt":"2026-10-19T10:00:00Z"}
+	]`), FORMAT_JSON)
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, expected)
+	}
+
+	if _, err := UnmarshalPredefined([]byte("- id: [id-1"), FORMAT_YAML); err == nil {
+		t.Fatalf(`result: {%v} but expected error`, err)
+	}
+}
+
+// TestMarshalPredefined calls MarshalPredefined([]PredefinedMockedRequest, string),
+// checking for a valid return value.
+func TestMarshalPredefined(t *testing.T) {
+	values := []PredefinedMockedRequest{
+		{MockedRequest: MockedRequest{
+			MockedRequestLight: MockedRequestLight{Id: "id-1", MockedRequestHeader: MockedRequestHeader{
+				Status: 200, ContentType: "text/plain", Charset: "UTF-8", Headers: map[string]string{"X-Version": "2"}}},
+			Body64: []byte("Hello\nWorld\n"),
+		}},
+		{MockedRequest: MockedRequest{
+			MockedRequestLight: MockedRequestLight{Id: "id-2", MockedRequestHeader: MockedRequestHeader{
+				Status: 200, ContentType: "application/octet-stream", Charset: "UTF-8"}},
+			Body64: []byte{0xff, 0xfe},
+		}},
+	}
+
+	data, err := MarshalPredefined(values, FORMAT_YAML)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(string(data), "  body: |\n    Hello\n    World\n") || !strings.Contains(string(data), `X-Version: "2"`) {
+		t.Fatalf(`result: {%v} but expected {%v}`, string(data), "body: |")
+	}
+
+	for _, format := range FORMATS {
+		data, _ := MarshalPredefined(values, format)
+		result, err := UnmarshalPredefined(data, format)
+		if err != nil || len(result) != 2 ||
+			!result[0].ToMockedRequest().Equals(*values[0].ToMockedRequest()) ||
+			!result[1].ToMockedRequest().Equals(*values[1].ToMockedRequest()) {
+			t.Fatalf(`result: {%v} but expected {%v}`, result, values)
+		}
+	}
+}
+
+// TestLoadPredefinedWithYAML calls LoadPredefined([]string),
+// checking for a valid return value.
+func TestLoadPredefinedWithYAML(t *testing.T) {
+	directory := t.TempDir()
+	os.WriteFile(filepath.Join(directory, "a.json"), []byte(`[{"id":"id-1","status":200,"contentType":"text/plain","charset":"UTF-8"}]`), 0644)
+	os.WriteFile(filepath.Join(directory, "b.yaml"), []byte("- id: id-2\n  status: 200\n  contentType: text/plain\n  charset: UTF-8\n  body: |\n    Hello World\n"), 0644)
+	os.WriteFile(filepath.Join(directory, "c.yml"), []byte("# nothing yet\n[]\n"), 0644)
+
+	values, files, err := LoadPredefined([]string{directory})
+	if err != nil || len(values) != 2 || len(files) != 3 || values[1].Id != "id-2" || values[1].Body != "Hello World\n" {
+		t.Fatalf(`result: {%v} but expected {%v}`, values, []string{"id-1", "id-2"})
+	}
+}